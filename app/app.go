@@ -2,14 +2,29 @@ package app
 
 import (
 	"clean-architecture/config"
+	"clean-architecture/migrations"
 	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/cartDto"
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/pkg/health"
+	"clean-architecture/pkg/jwtKey"
+	"clean-architecture/pkg/mailer"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/migration"
+	"clean-architecture/pkg/scheduler"
+	"clean-architecture/pkg/validation"
 	"clean-architecture/router"
+	"context"
 	"database/sql"
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -29,6 +44,14 @@ func initEnv() (dto.ConfigData, error) {
 	if port := os.Getenv("PORT"); port != "" {
 		configData.AppConfig.Port = port
 	}
+	configData.AppConfig.RequireMigratedSchema = os.Getenv("REQUIRE_MIGRATED_SCHEMA") == "true"
+	// TRUSTED_PROXIES lists the load balancers in front of the service, e.g. "10.0.0.0/8,192.168.1.10". Left empty,
+	// forwarding headers are ignored so a client cannot pick the ip the login limits count against
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			configData.AppConfig.TrustedProxies = append(configData.AppConfig.TrustedProxies, proxy)
+		}
+	}
 
 	dbHost := os.Getenv("DB_HOST")
 	dbPort := os.Getenv("DB_PORT")
@@ -65,13 +88,254 @@ func initEnv() (dto.ConfigData, error) {
 	if err != nil {
 		return configData, err
 	}
+
+	configData.DbConfig.QueryTimeout, err = parseDurationEnv("QUERY_TIMEOUT", "10s")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.DbConfig.ConnectRetries, err = parseIntEnv("DB_CONNECT_RETRIES", 5)
+	if err != nil {
+		return configData, err
+	}
+
+	configData.DbConfig.ConnectBackoff, err = parseDurationEnv("DB_CONNECT_BACKOFF", "1s")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.AppConfig.ShutdownGracePeriod, err = parseDurationEnv("SHUTDOWN_GRACE_PERIOD", "15s")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.AppConfig.ShutdownDrainDelay, err = parseDurationEnv("SHUTDOWN_DRAIN_DELAY", "5s")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.AgeConfig, err = parseAgeConfig(os.Getenv("LEGAL_AGE_RULES"), os.Getenv("DEFAULT_JURISDICTION"))
+	if err != nil {
+		return configData, err
+	}
+
+	configData.MailConfig = dto.MailConfig{
+		Driver:    os.Getenv("MAIL_DRIVER"),
+		Host:      os.Getenv("SMTP_HOST"),
+		Port:      os.Getenv("SMTP_PORT"),
+		Username:  os.Getenv("SMTP_USER"),
+		Password:  os.Getenv("SMTP_PASS"),
+		From:      os.Getenv("MAIL_FROM"),
+		OutboxDir: os.Getenv("MAIL_OUTBOX_DIR"),
+	}
+	if configData.MailConfig.From == "" {
+		configData.MailConfig.From = "no-reply@vapestore.local"
+	}
+
+	configData.EmailVerificationConfig.RequiredForLogin = os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+	configData.EmailVerificationConfig.VerifyURL = os.Getenv("EMAIL_VERIFICATION_URL")
+	configData.EmailVerificationConfig.TokenLifeTime, err = parseDurationEnv("EMAIL_VERIFICATION_LIFE_TIME", "24h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.PasswordResetConfig.ResetURL = os.Getenv("PASSWORD_RESET_URL")
+	configData.PasswordResetConfig.TokenLifeTime, err = parseDurationEnv("PASSWORD_RESET_LIFE_TIME", "1h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.LoginProtectionConfig, err = parseLoginProtectionConfig()
+	if err != nil {
+		return configData, err
+	}
+
+	configData.MfaConfig.Issuer = os.Getenv("MFA_ISSUER")
+	if configData.MfaConfig.Issuer == "" {
+		configData.MfaConfig.Issuer = "Vapestore"
+	}
+	requiredRoles := os.Getenv("MFA_REQUIRED_ROLES")
+	if requiredRoles == "" {
+		requiredRoles = "admin,store_manager"
+	}
+	for _, role := range strings.Split(requiredRoles, ",") {
+		configData.MfaConfig.RequiredRoles = append(configData.MfaConfig.RequiredRoles, strings.TrimSpace(role))
+	}
+	configData.MfaConfig.PendingTokenLifeTime, err = parseDurationEnv("MFA_PENDING_LIFE_TIME", "5m")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.RetentionConfig.DeletedUserRetention, err = parseDurationEnv("DELETED_USER_RETENTION", "720h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.RetentionConfig.UserPurgeInterval, err = parseDurationEnv("USER_PURGE_INTERVAL", "1h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.InventoryConfig.ReservationLifeTime, err = parseDurationEnv("STOCK_RESERVATION_LIFE_TIME", "15m")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.InventoryConfig.ReservationExpiryInterval, err = parseDurationEnv("STOCK_RESERVATION_EXPIRY_INTERVAL", "1m")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.InventoryConfig.OnlineLocationCode = os.Getenv("ONLINE_LOCATION_CODE")
+	if configData.InventoryConfig.OnlineLocationCode == "" {
+		configData.InventoryConfig.OnlineLocationCode = "ONLINE"
+	}
+
+	configData.CartConfig.GuestCartLifeTime, err = parseDurationEnv("GUEST_CART_LIFE_TIME", "168h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.CartConfig.UserCartLifeTime, err = parseDurationEnv("USER_CART_LIFE_TIME", "720h")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.CartConfig.ExpiryInterval, err = parseDurationEnv("CART_EXPIRY_INTERVAL", "1h")
+	if err != nil {
+		return configData, err
+	}
+
+	shippingFee, err := parseIntEnv("ORDER_SHIPPING_FEE", 20000)
+	if err != nil {
+		return configData, err
+	}
+	configData.OrderConfig.ShippingFee = int64(shippingFee)
+
+	freeShippingThreshold, err := parseIntEnv("ORDER_FREE_SHIPPING_THRESHOLD", 0)
+	if err != nil {
+		return configData, err
+	}
+	configData.OrderConfig.FreeShippingThreshold = int64(freeShippingThreshold)
+
+	configData.OrderConfig.TaxRate, err = parseIntEnv("ORDER_TAX_RATE", 1100)
+	if err != nil {
+		return configData, err
+	}
+
+	configData.JwtConfig.KeysFile = os.Getenv("JWT_KEYS_FILE")
+	if configData.JwtConfig.KeysFile == "" {
+		configData.JwtConfig.KeysFile = "keys/jwt-keys.json"
+	}
+
+	configData.JwtConfig.AccessTokenLifeTime, err = parseDurationEnv("ACCESS_TOKEN_LIFE_TIME", "15m")
+	if err != nil {
+		return configData, err
+	}
+
+	configData.JwtConfig.RefreshTokenLifeTime, err = parseDurationEnv("REFRESH_TOKEN_LIFE_TIME", "720h")
+	if err != nil {
+		return configData, err
+	}
 	return configData, nil
 }
 
-func initializeDomainModule(r *gin.Engine, db *sql.DB) {
+// parseDurationEnv reads a duration from the environment, falling back to def when unset
+func parseDurationEnv(key, def string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		value = def
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return duration, nil
+}
+
+// parseIntEnv reads an integer from the environment, falling back to def when unset
+func parseIntEnv(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return result, nil
+}
+
+func parseLoginProtectionConfig() (dto.LoginProtectionConfig, error) {
+	var err error
+	loginConfig := dto.LoginProtectionConfig{Store: os.Getenv("LOGIN_ATTEMPT_STORE")}
+	if loginConfig.Store == "" {
+		loginConfig.Store = "postgres"
+	}
+
+	if loginConfig.MaxAccountFailures, err = parseIntEnv("LOGIN_MAX_ACCOUNT_FAILURES", 5); err != nil {
+		return loginConfig, err
+	}
+	if loginConfig.MaxIPFailures, err = parseIntEnv("LOGIN_MAX_IP_FAILURES", 20); err != nil {
+		return loginConfig, err
+	}
+	if loginConfig.FailureWindow, err = parseDurationEnv("LOGIN_FAILURE_WINDOW", "15m"); err != nil {
+		return loginConfig, err
+	}
+	if loginConfig.LockDuration, err = parseDurationEnv("LOGIN_LOCK_DURATION", "15m"); err != nil {
+		return loginConfig, err
+	}
+	if loginConfig.BaseDelay, err = parseDurationEnv("LOGIN_BASE_DELAY", "250ms"); err != nil {
+		return loginConfig, err
+	}
+	if loginConfig.MaxDelay, err = parseDurationEnv("LOGIN_MAX_DELAY", "5s"); err != nil {
+		return loginConfig, err
+	}
+
+	return loginConfig, nil
+}
+
+// parseAgeConfig reads rules shaped like "ID=21,MY=21", Indonesia at 21 is used when nothing is set
+func parseAgeConfig(rules, defaultJurisdiction string) (dto.AgeConfig, error) {
+	if rules == "" {
+		rules = "ID=21"
+	}
+	if defaultJurisdiction == "" {
+		defaultJurisdiction = "ID"
+	}
+
+	ageConfig := dto.AgeConfig{
+		LegalAges:           make(map[string]int),
+		DefaultJurisdiction: strings.ToUpper(defaultJurisdiction),
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		parts := strings.SplitN(strings.TrimSpace(rule), "=", 2)
+		if len(parts) != 2 {
+			return ageConfig, fmt.Errorf("LEGAL_AGE_RULES: invalid rule %q", rule)
+		}
+
+		age, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return ageConfig, fmt.Errorf("LEGAL_AGE_RULES: %w", err)
+		}
+		ageConfig.LegalAges[strings.ToUpper(parts[0])] = age
+	}
+
+	if _, ok := ageConfig.LegalAges[ageConfig.DefaultJurisdiction]; !ok {
+		return ageConfig, fmt.Errorf("DEFAULT_JURISDICTION %s has no legal age rule", ageConfig.DefaultJurisdiction)
+	}
+
+	return ageConfig, nil
+}
+
+func initializeDomainModule(r *gin.Engine, db *sql.DB, configData dto.ConfigData, keySet *jwtKey.KeySet, mail mailer.Mailer, jobs *scheduler.Scheduler) {
+	r.GET("/.well-known/jwks.json", keySet.JwksHandler)
+
 	apiGroup := r.Group("/api")
 	v1Group := apiGroup.Group("/v1")
-	router.InitRoute(v1Group, db)
+	router.InitRoute(v1Group, db, configData, mail, jobs)
 }
 
 func RunService() {
@@ -83,14 +347,23 @@ func RunService() {
 		return
 	}
 
+	keySet, err := jwtKey.LoadKeySet(configData.JwtConfig.KeysFile)
+	if err != nil {
+		log.Error().Msg("RunService.LoadKeySet.err : " + err.Error())
+		return
+	}
+	middleware.InitJwtKeySet(keySet)
+
 	conn, err := config.ConnectToDB(configData, log.Logger)
 	if err != nil {
 		log.Error().Msg("RunService.ConnectToDB.err : " + err.Error())
+		return
 	}
 
 	duration, err := time.ParseDuration(configData.DbConfig.MaxLifeTime)
 	if err != nil {
 		log.Error().Msg("RunService.duration.err : " + err.Error())
+		return
 	}
 
 	// set max conn
@@ -105,15 +378,36 @@ func RunService() {
 		}
 	}()
 
+	if configData.AppConfig.RequireMigratedSchema {
+		migrator, err := migration.NewMigrator(conn, migrations.FS)
+		if err != nil {
+			log.Error().Msg("RunService.NewMigrator.err : " + err.Error())
+			return
+		}
+		if err := checkSchema(migrator); err != nil {
+			log.Error().Msg("RunService.checkSchema.err : " + err.Error())
+			return
+		}
+	}
+
+	if err := validation.RegisterValidations(); err != nil {
+		log.Error().Msg("RunService.RegisterValidations.err : " + err.Error())
+		return
+	}
+
 	time.Local = time.FixedZone("Asia/Jakarta", 7*60*59)
 	r := gin.New()
+	if err := r.SetTrustedProxies(configData.AppConfig.TrustedProxies); err != nil {
+		log.Error().Msg("RunService.SetTrustedProxies.err : " + err.Error())
+		return
+	}
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins: false,
 		AllowOrigins:    []string{"*"},
-		AllowMethods:    []string{"POST", "DELETE", "GET", "OPTIONS", "PUT"},
+		AllowMethods:    []string{"POST", "DELETE", "GET", "OPTIONS", "PUT", "PATCH"},
 		AllowHeaders: []string{
 			"Origin", "Content-Type",
-			"Authorization",
+			"Authorization", cartDto.CartTokenHeader, orderDto.IdempotencyKeyHeader,
 		},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -126,17 +420,71 @@ func RunService() {
 		logger.WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
 			return l.Output(os.Stdout).With().Logger()
 		}),
+		logger.WithSkipPath([]string{"/healthz", "/readyz"}),
 	))
 
 	r.Use(gin.Recovery())
+	// the deadline wraps the error handler, so the request context is still live when errors are turned into responses
+	r.Use(middleware.RequestTimeout(configData.DbConfig.QueryTimeout))
+	r.Use(middleware.ErrorHandler())
 
-	initializeDomainModule(r, conn)
+	healthChecker := health.NewChecker(conn)
+	r.GET("/healthz", healthChecker.Liveness)
+	r.GET("/readyz", healthChecker.Readiness)
+
+	mail, err := mailer.NewMailer(configData.MailConfig)
+	if err != nil {
+		log.Error().Msg("RunService.NewMailer.err : " + err.Error())
+		return
+	}
+
+	jobs := scheduler.NewScheduler()
+	initializeDomainModule(r, conn, configData, keySet, mail, jobs)
 
 	version := "0.0.1"
 	log.Info().Msg(fmt.Sprintf("Service Running version %s", version))
 	addr := flag.String("port: ", ":"+configData.AppConfig.Port, "Address to listen and serve")
-	if err := r.Run(*addr); err != nil {
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: r,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	jobs.Start(context.Background())
+	defer jobs.Stop()
+
+	select {
+	case err := <-serveErr:
 		log.Error().Msg(err.Error())
 		return
+	case sig := <-stop:
+		log.Info().Msg(fmt.Sprintf("Received %s, closing the listener in %s and draining requests for up to %s", sig, configData.AppConfig.ShutdownDrainDelay, configData.AppConfig.ShutdownGracePeriod))
+	}
+
+	healthChecker.SetShuttingDown()
+	// keep serving while the load balancers notice the failing readiness probe, a second signal skips the wait
+	drain := time.NewTimer(configData.AppConfig.ShutdownDrainDelay)
+	select {
+	case <-drain.C:
+	case <-stop:
+		drain.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), configData.AppConfig.ShutdownGracePeriod)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error().Msg("RunService.Shutdown.err : " + err.Error())
+		return
 	}
+	log.Info().Msg("Service stopped")
 }
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"clean-architecture/config"
+	"clean-architecture/model/dto/clientDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/client/clientRepository"
+	"clean-architecture/src/client/clientUseCase"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunClient handles `client create <name> <grant_types> [scopes]`, used to bootstrap the first
+// client before any admin can log in through /oauth/token
+func RunClient(args []string) error {
+	if len(args) < 3 || args[0] != "create" {
+		return errors.New("usage: client create <name> <grant_types> [scopes]")
+	}
+
+	configData, err := initEnv()
+	if err != nil {
+		return err
+	}
+
+	conn, err := config.ConnectToDB(configData, log.Logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	request := &clientDto.CreateClientRequest{
+		Name:       args[1],
+		GrantTypes: splitList(args[2]),
+	}
+	for _, grantType := range request.GrantTypes {
+		if grantType != entity.GrantTypePassword && grantType != entity.GrantTypeClientCredentials {
+			return fmt.Errorf("unsupported grant type %q", grantType)
+		}
+	}
+	if len(args) > 3 {
+		request.Scopes = splitList(args[3])
+	}
+
+	clientUC := clientUseCase.NewClientUseCase(clientRepository.NewClientRepository(conn), configData)
+	created, err := clientUC.CreateClient(context.Background(), request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("client_id:    ", created.Client.ClientID)
+	fmt.Println("client_secret:", created.ClientSecret)
+	fmt.Println("the secret is shown only once, store it now")
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"clean-architecture/config"
+	"clean-architecture/migrations"
+	"clean-architecture/pkg/migration"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+const migrationsDir = "migrations"
+
+// RunMigrate handles the `migrate up|down [steps]|status|create <name>` subcommands
+func RunMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [steps]|status|create <name>")
+	}
+
+	// create only scaffolds files, it must not need a database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		files, err := migration.Create(migrationsDir, args[1])
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return nil
+	}
+
+	configData, err := initEnv()
+	if err != nil {
+		return err
+	}
+
+	conn, err := config.ConnectToDB(configData, log.Logger)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := migration.NewMigrator(conn, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New("steps must be a positive number")
+			}
+		}
+		count, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("rolled back %d migration(s)\n", count)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d  %-40s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
+
+// checkSchema refuses to continue when embedded migrations have not been applied yet
+func checkSchema(migrator *migration.Migrator) error {
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema is behind by %d migration(s), run `migrate up`", pending)
+	}
+	return nil
+}
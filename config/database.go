@@ -2,13 +2,20 @@ package config
 
 import (
 	"clean-architecture/model/dto"
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog"
 )
 
+const (
+	pingTimeout    = 5 * time.Second
+	maxPingBackoff = 30 * time.Second
+)
+
 func ConnectToDB(in dto.ConfigData, logger zerolog.Logger) (*sql.DB, error) {
 	logger.Info().Msg("Trying connect to db..")
 
@@ -16,10 +23,34 @@ func ConnectToDB(in dto.ConfigData, logger zerolog.Logger) (*sql.DB, error) {
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to ping db")
+		logger.Error().Err(err).Msg("Failed to open db")
 		return nil, err
 	}
 
+	// sql.Open never dials, ping with exponential backoff so a database that is still booting is waited for
+	backoff := in.DbConfig.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+
+		if attempt >= in.DbConfig.ConnectRetries {
+			logger.Error().Err(err).Msg("Failed to ping db")
+			db.Close()
+			return nil, err
+		}
+
+		logger.Warn().Err(err).Msg(fmt.Sprintf("Failed to ping db, attempt %d/%d, retrying in %s", attempt, in.DbConfig.ConnectRetries, backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxPingBackoff {
+			backoff = maxPingBackoff
+		}
+	}
+
 	logger.Info().Msg("Successfully connected to db")
 	return db, nil
 }
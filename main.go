@@ -2,8 +2,27 @@ package main
 
 import (
 	"clean-architecture/app"
+	"os"
+
+	"github.com/rs/zerolog/log"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.RunMigrate(os.Args[2:]); err != nil {
+			log.Error().Msg(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "client" {
+		if err := app.RunClient(os.Args[2:]); err != nil {
+			log.Error().Msg(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	app.RunService()
 }
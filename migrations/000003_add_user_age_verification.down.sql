@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS age_verifications;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS age_verification_status,
+    DROP COLUMN IF EXISTS jurisdiction,
+    DROP COLUMN IF EXISTS date_of_birth;
@@ -0,0 +1,17 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS date_of_birth           DATE,
+    ADD COLUMN IF NOT EXISTS jurisdiction            CHAR(2)     NOT NULL DEFAULT 'ID',
+    ADD COLUMN IF NOT EXISTS age_verification_status VARCHAR(32) NOT NULL DEFAULT 'pending';
+
+CREATE TABLE IF NOT EXISTS age_verifications (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            UUID         NOT NULL REFERENCES users (id),
+    method             VARCHAR(32)  NOT NULL,
+    document_reference VARCHAR(512) NOT NULL DEFAULT '',
+    status             VARCHAR(32)  NOT NULL,
+    reviewer_id        UUID REFERENCES users (id),
+    note               TEXT         NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS age_verifications_user_id_idx ON age_verifications (user_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+ALTER TABLE token_families DROP COLUMN IF EXISTS mfa_authenticated;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS mfa_last_step,
+    DROP COLUMN IF EXISTS mfa_enabled_at,
+    DROP COLUMN IF EXISTS mfa_secret;
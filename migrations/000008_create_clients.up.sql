@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS clients (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id   VARCHAR(64)  NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    secret_hash CHAR(64)     NOT NULL,
+    scopes      TEXT[]       NOT NULL DEFAULT '{}',
+    grant_types TEXT[]       NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS clients_client_id_key ON clients (client_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_changes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    changed_by UUID,
+    fields     TEXT[]      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_changes_user_id_idx ON user_changes (user_id, created_at);
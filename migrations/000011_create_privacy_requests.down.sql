@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS privacy_requests;
+
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
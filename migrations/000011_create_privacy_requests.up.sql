@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS privacy_requests (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type         VARCHAR(16) NOT NULL,
+    status       VARCHAR(16) NOT NULL DEFAULT 'pending',
+    requested_by UUID        NOT NULL,
+    handled_by   UUID,
+    note         TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS privacy_requests_user_id_idx ON privacy_requests (user_id, created_at);
+CREATE INDEX IF NOT EXISTS privacy_requests_status_idx ON privacy_requests (status, created_at);
+
+-- at most one erasure can wait for review per user
+CREATE UNIQUE INDEX IF NOT EXISTS privacy_requests_pending_erasure_key
+    ON privacy_requests (user_id) WHERE type = 'erasure' AND status = 'pending';
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS product_options (
+    product_id UUID        NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    name       VARCHAR(64) NOT NULL,
+    position   INT         NOT NULL DEFAULT 0,
+    "values"   TEXT[]      NOT NULL DEFAULT '{}',
+    PRIMARY KEY (product_id, name)
+);
+
+-- option_key is the canonical option combination, see entity.OptionKey
+CREATE TABLE IF NOT EXISTS product_variants (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID        NOT NULL REFERENCES products (id),
+    sku        VARCHAR(64) NOT NULL,
+    barcode    VARCHAR(64),
+    price      BIGINT      NOT NULL CHECK (price >= 0),
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    options    JSONB       NOT NULL DEFAULT '{}',
+    option_key TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_sku_key ON product_variants (sku);
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_barcode_key ON product_variants (barcode) WHERE barcode IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_option_key ON product_variants (product_id, option_key);
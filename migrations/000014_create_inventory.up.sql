@@ -0,0 +1,43 @@
+-- stock_levels caches the ledger totals per variant and is the row every stock change locks
+CREATE TABLE IF NOT EXISTS stock_levels (
+    variant_id UUID PRIMARY KEY REFERENCES product_variants (id),
+    on_hand    INT         NOT NULL DEFAULT 0 CHECK (on_hand >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    variant_id  UUID        NOT NULL REFERENCES product_variants (id),
+    type        VARCHAR(16) NOT NULL,
+    quantity    INT         NOT NULL CHECK (quantity <> 0),
+    reason_code VARCHAR(32) NOT NULL DEFAULT '',
+    reference   VARCHAR(128) NOT NULL DEFAULT '',
+    note        TEXT        NOT NULL DEFAULT '',
+    created_by  UUID,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_variant_id_idx ON stock_movements (variant_id, created_at);
+
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only, post a correcting movement instead';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    variant_id  UUID         NOT NULL REFERENCES product_variants (id),
+    quantity    INT          NOT NULL CHECK (quantity > 0),
+    reference   VARCHAR(128) NOT NULL DEFAULT '',
+    status      VARCHAR(16)  NOT NULL DEFAULT 'active',
+    expires_at  TIMESTAMPTZ  NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS stock_reservations_active_idx ON stock_reservations (variant_id, expires_at) WHERE status = 'active';
@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS stock_transfer_lines;
+DROP TABLE IF EXISTS stock_transfers;
+
+DROP INDEX IF EXISTS stock_reservations_active_idx;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS location_id;
+CREATE INDEX IF NOT EXISTS stock_reservations_active_idx ON stock_reservations (variant_id, expires_at) WHERE status = 'active';
+
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS location_id;
+
+-- fold the per-location stock back into one row per variant
+DROP INDEX IF EXISTS stock_levels_variant_id_idx;
+ALTER TABLE stock_levels DROP CONSTRAINT stock_levels_pkey;
+ALTER TABLE stock_levels ALTER COLUMN location_id DROP NOT NULL;
+WITH removed AS (DELETE FROM stock_levels RETURNING variant_id, on_hand, updated_at)
+INSERT INTO stock_levels (variant_id, on_hand, updated_at)
+SELECT variant_id, SUM(on_hand), MAX(updated_at) FROM removed GROUP BY variant_id;
+ALTER TABLE stock_levels DROP COLUMN location_id;
+ALTER TABLE stock_levels ADD PRIMARY KEY (variant_id);
+
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code          VARCHAR(32)  NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    type          VARCHAR(16)  NOT NULL,
+    address_line  TEXT         NOT NULL DEFAULT '',
+    city          VARCHAR(128) NOT NULL DEFAULT '',
+    province      VARCHAR(128) NOT NULL DEFAULT '',
+    postal_code   VARCHAR(16)  NOT NULL DEFAULT '',
+    phone         VARCHAR(32)  NOT NULL DEFAULT '',
+    opening_hours JSONB        NOT NULL DEFAULT '[]',
+    active        BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS locations_code_key ON locations (code);
+
+-- the stock recorded before locations existed belongs to the online warehouse
+INSERT INTO locations (code, name, type) VALUES ('ONLINE', 'Online warehouse', 'warehouse');
+
+ALTER TABLE stock_levels ADD COLUMN location_id UUID REFERENCES locations (id);
+UPDATE stock_levels SET location_id = (SELECT id FROM locations WHERE code = 'ONLINE');
+ALTER TABLE stock_levels ALTER COLUMN location_id SET NOT NULL;
+ALTER TABLE stock_levels DROP CONSTRAINT stock_levels_pkey;
+ALTER TABLE stock_levels ADD PRIMARY KEY (location_id, variant_id);
+CREATE INDEX IF NOT EXISTS stock_levels_variant_id_idx ON stock_levels (variant_id);
+
+ALTER TABLE stock_movements DISABLE TRIGGER stock_movements_append_only;
+ALTER TABLE stock_movements ADD COLUMN location_id UUID REFERENCES locations (id);
+UPDATE stock_movements SET location_id = (SELECT id FROM locations WHERE code = 'ONLINE');
+ALTER TABLE stock_movements ALTER COLUMN location_id SET NOT NULL;
+ALTER TABLE stock_movements ENABLE TRIGGER stock_movements_append_only;
+CREATE INDEX IF NOT EXISTS stock_movements_location_id_idx ON stock_movements (location_id, created_at);
+
+ALTER TABLE stock_reservations ADD COLUMN location_id UUID REFERENCES locations (id);
+UPDATE stock_reservations SET location_id = (SELECT id FROM locations WHERE code = 'ONLINE');
+ALTER TABLE stock_reservations ALTER COLUMN location_id SET NOT NULL;
+DROP INDEX IF EXISTS stock_reservations_active_idx;
+CREATE INDEX IF NOT EXISTS stock_reservations_active_idx ON stock_reservations (location_id, variant_id, expires_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_location_id UUID        NOT NULL REFERENCES locations (id),
+    to_location_id   UUID        NOT NULL REFERENCES locations (id),
+    status           VARCHAR(16) NOT NULL DEFAULT 'requested',
+    note             TEXT        NOT NULL DEFAULT '',
+    requested_by     UUID,
+    shipped_by       UUID,
+    received_by      UUID,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    shipped_at       TIMESTAMPTZ,
+    received_at      TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX IF NOT EXISTS stock_transfers_status_idx ON stock_transfers (status, created_at);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    transfer_id        UUID NOT NULL REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    variant_id         UUID NOT NULL REFERENCES product_variants (id),
+    quantity_requested INT  NOT NULL CHECK (quantity_requested > 0),
+    quantity_shipped   INT,
+    quantity_received  INT,
+    discrepancy_note   TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (transfer_id, variant_id)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- a user has one cart, guest carts have no user and are only reachable through their signed token
+CREATE UNIQUE INDEX IF NOT EXISTS carts_user_id_key ON carts (user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS carts_updated_at_idx ON carts (updated_at);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    cart_id    UUID        NOT NULL REFERENCES carts (id) ON DELETE CASCADE,
+    variant_id UUID        NOT NULL REFERENCES product_variants (id) ON DELETE CASCADE,
+    quantity   INT         NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT      NOT NULL CHECK (unit_price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (cart_id, variant_id)
+);
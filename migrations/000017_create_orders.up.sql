@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id           UUID         NOT NULL REFERENCES users (id),
+    status            VARCHAR(16)  NOT NULL DEFAULT 'placed',
+    fulfilment_method VARCHAR(16)  NOT NULL,
+    location_id       UUID         NOT NULL REFERENCES locations (id),
+    shipping_address  JSONB,
+    subtotal          BIGINT       NOT NULL CHECK (subtotal >= 0),
+    discount          BIGINT       NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    shipping_fee      BIGINT       NOT NULL DEFAULT 0 CHECK (shipping_fee >= 0),
+    tax               BIGINT       NOT NULL DEFAULT 0 CHECK (tax >= 0),
+    total             BIGINT       NOT NULL CHECK (total >= 0),
+    idempotency_key   VARCHAR(128) NOT NULL,
+    request_hash      CHAR(64)     NOT NULL,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- a retried submission carries the same key and must find the order of the first attempt
+CREATE UNIQUE INDEX IF NOT EXISTS orders_idempotency_key ON orders (user_id, idempotency_key);
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id, created_at);
+
+-- names, SKUs and prices are copied so later catalog changes never rewrite an order
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id     UUID         NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    variant_id   UUID         NOT NULL REFERENCES product_variants (id),
+    product_id   UUID         NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    sku          VARCHAR(64)  NOT NULL,
+    options      JSONB        NOT NULL DEFAULT '{}',
+    quantity     INT          NOT NULL CHECK (quantity > 0),
+    unit_price   BIGINT       NOT NULL CHECK (unit_price >= 0),
+    line_total   BIGINT       NOT NULL CHECK (line_total >= 0),
+    PRIMARY KEY (order_id, variant_id)
+);
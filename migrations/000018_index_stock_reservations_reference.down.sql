@@ -0,0 +1 @@
+DROP INDEX IF EXISTS stock_reservations_reference_idx;
@@ -0,0 +1,2 @@
+-- checkouts hold, consume and release their reservations by reference
+CREATE INDEX IF NOT EXISTS stock_reservations_reference_idx ON stock_reservations (reference) WHERE status = 'active';
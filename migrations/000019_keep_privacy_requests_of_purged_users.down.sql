@@ -0,0 +1,2 @@
+DELETE FROM privacy_requests WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = privacy_requests.user_id);
+ALTER TABLE privacy_requests ADD CONSTRAINT privacy_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- the privacy request log is the audit trail of exports and erasures, it has to outlive the users it is about
+ALTER TABLE privacy_requests DROP CONSTRAINT IF EXISTS privacy_requests_user_id_fkey;
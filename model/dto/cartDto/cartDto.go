@@ -0,0 +1,58 @@
+package cartDto
+
+import "time"
+
+const (
+	// CartTokenHeader carries the signed token of a guest cart, on requests and on login
+	CartTokenHeader = "X-Cart-Token"
+	MaxItemQuantity = 99
+	MaxCartLines    = 100
+)
+
+type (
+	AddItemRequest struct {
+		VariantID string `json:"variant_id" binding:"required,uuid"`
+		Quantity  int    `json:"quantity" binding:"required,min=1,max=99"`
+	}
+
+	UpdateItemRequest struct {
+		Quantity int `json:"quantity" binding:"required,min=1,max=99"`
+	}
+
+	// CartOwner identifies the cart of a request, the user id wins over a cart token
+	CartOwner struct {
+		UserID    string
+		CartToken string
+	}
+
+	// CartResponse is a cart priced at the time it is read. Subtotal only counts lines that can be bought,
+	// CartToken is renewed on every response of a guest cart and empty for user carts.
+	CartResponse struct {
+		ID                  string              `json:"id"`
+		CartToken           string              `json:"cart_token,omitempty"`
+		Items               []*CartLineResponse `json:"items"`
+		ItemCount           int                 `json:"item_count"`
+		Subtotal            int64               `json:"subtotal"`
+		HasUnavailableItems bool                `json:"has_unavailable_items"`
+		HasPriceChanges     bool                `json:"has_price_changes"`
+		UpdatedAt           time.Time           `json:"updated_at"`
+	}
+
+	// CartLineResponse flags a line that cannot be bought as is, PreviousPrice is set when the price changed since it was last seen
+	CartLineResponse struct {
+		VariantID         string            `json:"variant_id"`
+		ProductID         string            `json:"product_id"`
+		ProductName       string            `json:"product_name"`
+		ProductSlug       string            `json:"product_slug"`
+		SKU               string            `json:"sku"`
+		Options           map[string]string `json:"options"`
+		Quantity          int               `json:"quantity"`
+		UnitPrice         int64             `json:"unit_price"`
+		PreviousPrice     *int64            `json:"previous_price,omitempty"`
+		LineTotal         int64             `json:"line_total"`
+		Available         int               `json:"available"`
+		Unavailable       bool              `json:"unavailable"`
+		InsufficientStock bool              `json:"insufficient_stock"`
+		PriceChanged      bool              `json:"price_changed"`
+	}
+)
@@ -0,0 +1,38 @@
+package clientDto
+
+import "clean-architecture/model/entity"
+
+type (
+	CreateClientRequest struct {
+		Name       string   `json:"name" binding:"required"`
+		Scopes     []string `json:"scopes"`
+		GrantTypes []string `json:"grant_types" binding:"required,min=1,dive,oneof=password client_credentials"`
+	}
+
+	UpdateClientRequest struct {
+		Name       string   `json:"name" binding:"required"`
+		Scopes     []string `json:"scopes"`
+		GrantTypes []string `json:"grant_types" binding:"required,min=1,dive,oneof=password client_credentials"`
+	}
+
+	// ClientSecretResponse is the only place a client secret is ever returned in clear
+	ClientSecretResponse struct {
+		Client       *entity.Client `json:"client"`
+		ClientSecret string         `json:"client_secret"`
+	}
+
+	TokenRequest struct {
+		GrantType string `form:"grant_type" binding:"required"`
+		Username  string `form:"username"`
+		Password  string `form:"password"`
+		Scope     string `form:"scope"`
+	}
+
+	OAuthTokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		Scope        string `json:"scope,omitempty"`
+	}
+)
@@ -1,24 +1,136 @@
 package dto
 
+import "time"
+
 type (
 	ConfigData struct {
-		DbConfig  DbConfig
-		AppConfig AppConfig
+		DbConfig                DbConfig
+		AppConfig               AppConfig
+		JwtConfig               JwtConfig
+		AgeConfig               AgeConfig
+		MailConfig              MailConfig
+		EmailVerificationConfig EmailVerificationConfig
+		PasswordResetConfig     PasswordResetConfig
+		LoginProtectionConfig   LoginProtectionConfig
+		MfaConfig               MfaConfig
+		RetentionConfig         RetentionConfig
+		InventoryConfig         InventoryConfig
+		CartConfig              CartConfig
+		OrderConfig             OrderConfig
 	}
 
 	DbConfig struct {
-		Host        string
-		DbPort      string
-		User        string
-		Pass        string
-		Database    string
-		MaxIdle     int
-		MaxConn     int
-		MaxLifeTime string
-		LogMode     int
+		Host           string
+		DbPort         string
+		User           string
+		Pass           string
+		Database       string
+		MaxIdle        int
+		MaxConn        int
+		MaxLifeTime    string
+		LogMode        int
+		QueryTimeout   time.Duration
+		ConnectRetries int
+		ConnectBackoff time.Duration
 	}
 
 	AppConfig struct {
-		Port string
+		Port                  string
+		RequireMigratedSchema bool
+		ShutdownGracePeriod   time.Duration
+		// ShutdownDrainDelay is how long /readyz reports unready before the listener closes, so load balancers stop
+		// routing new requests first
+		ShutdownDrainDelay time.Duration
+		// TrustedProxies are the proxy addresses or CIDRs whose X-Forwarded-For is believed for the client ip,
+		// with none set the client ip is always the remote address of the connection
+		TrustedProxies []string
+	}
+
+	AgeConfig struct {
+		// LegalAges maps an ISO 3166-1 alpha-2 jurisdiction to its minimum purchase age
+		LegalAges           map[string]int
+		DefaultJurisdiction string
+	}
+
+	MailConfig struct {
+		// Driver is either smtp or outbox
+		Driver    string
+		Host      string
+		Port      string
+		Username  string
+		Password  string
+		From      string
+		OutboxDir string
+	}
+
+	EmailVerificationConfig struct {
+		RequiredForLogin bool
+		TokenLifeTime    time.Duration
+		// VerifyURL is the frontend page the emailed link points to, the token is appended as ?token=
+		VerifyURL string
+	}
+
+	PasswordResetConfig struct {
+		TokenLifeTime time.Duration
+		// ResetURL is the frontend page the emailed link points to, the token is appended as ?token=
+		ResetURL string
+	}
+
+	LoginProtectionConfig struct {
+		// Store is either postgres or memory, memory only protects a single instance
+		Store              string
+		MaxAccountFailures int
+		MaxIPFailures      int
+		FailureWindow      time.Duration
+		LockDuration       time.Duration
+		BaseDelay          time.Duration
+		MaxDelay           time.Duration
+	}
+
+	MfaConfig struct {
+		// RequiredRoles may only use their permissions after a login that passed the totp step
+		RequiredRoles        []string
+		Issuer               string
+		PendingTokenLifeTime time.Duration
+	}
+
+	RetentionConfig struct {
+		// DeletedUserRetention is how long a soft-deleted user can be restored before it is purged
+		DeletedUserRetention time.Duration
+		// UserPurgeInterval is how often the purge job runs, zero disables it
+		UserPurgeInterval time.Duration
+	}
+
+	InventoryConfig struct {
+		// ReservationLifeTime is how long stock stays held for a checkout before it returns to sale
+		ReservationLifeTime time.Duration
+		// ReservationExpiryInterval is how often lapsed reservations are expired, zero disables the job
+		ReservationExpiryInterval time.Duration
+		// OnlineLocationCode is the location online carts and orders are served from
+		OnlineLocationCode string
+	}
+
+	CartConfig struct {
+		// GuestCartLifeTime is how long an untouched guest cart and its token live
+		GuestCartLifeTime time.Duration
+		// UserCartLifeTime is how long an untouched cart of a logged-in user is kept
+		UserCartLifeTime time.Duration
+		// ExpiryInterval is how often abandoned carts are removed, zero disables the job
+		ExpiryInterval time.Duration
+	}
+
+	// OrderConfig amounts are in whole rupiah
+	OrderConfig struct {
+		ShippingFee int64
+		// FreeShippingThreshold waives the shipping fee from this subtotal after discount on, zero never waives it
+		FreeShippingThreshold int64
+		// TaxRate is charged on the subtotal after discount, in basis points so 1100 is 11%
+		TaxRate int
+	}
+
+	JwtConfig struct {
+		KeysFile             string
+		AccessTokenLifeTime  time.Duration
+		RefreshTokenLifeTime time.Duration
 	}
 )
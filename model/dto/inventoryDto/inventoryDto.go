@@ -0,0 +1,84 @@
+package inventoryDto
+
+import "clean-architecture/model/entity"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type (
+	// AdjustmentRequest corrects the stock of a variant at a location, a negative quantity takes stock out
+	AdjustmentRequest struct {
+		LocationID string `json:"location_id" binding:"required,uuid"`
+		VariantID  string `json:"variant_id" binding:"required,uuid"`
+		Quantity   int    `json:"quantity" binding:"required"`
+		ReasonCode string `json:"reason_code" binding:"required,oneof=count_correction damaged expired lost found other"`
+		Note       string `json:"note" binding:"max=1000"`
+	}
+
+	// ReceiptRequest books incoming stock, Reference is the supplier invoice or delivery note
+	ReceiptRequest struct {
+		LocationID string `json:"location_id" binding:"required,uuid"`
+		VariantID  string `json:"variant_id" binding:"required,uuid"`
+		Quantity   int    `json:"quantity" binding:"required,min=1"`
+		Reference  string `json:"reference" binding:"max=128"`
+		Note       string `json:"note" binding:"max=1000"`
+	}
+
+	// TransferRequest asks for stock to be moved from one location to another
+	TransferRequest struct {
+		FromLocationID string                `json:"from_location_id" binding:"required,uuid"`
+		ToLocationID   string                `json:"to_location_id" binding:"required,uuid"`
+		Note           string                `json:"note" binding:"max=1000"`
+		Lines          []TransferLineRequest `json:"lines" binding:"required,min=1,max=200,dive"`
+	}
+
+	TransferLineRequest struct {
+		VariantID string `json:"variant_id" binding:"required,uuid"`
+		Quantity  int    `json:"quantity" binding:"required,min=1"`
+	}
+
+	// ReceiveTransferRequest confirms arrival, lines left out are taken to have arrived exactly as shipped
+	ReceiveTransferRequest struct {
+		Lines []ReceivedLineRequest `json:"lines" binding:"omitempty,dive"`
+	}
+
+	ReceivedLineRequest struct {
+		VariantID        string `json:"variant_id" binding:"required,uuid"`
+		QuantityReceived *int   `json:"quantity_received" binding:"required,min=0"`
+		DiscrepancyNote  string `json:"discrepancy_note" binding:"max=1000"`
+	}
+
+	MovementListQuery struct {
+		VariantID  string
+		LocationID string
+		Page       int
+		Size       int
+	}
+
+	// StockListQuery filters the stock report, SKU is an exact match
+	StockListQuery struct {
+		LocationID string
+		SKU        string
+		Page       int
+		Size       int
+	}
+
+	TransferListQuery struct {
+		Status     entity.TransferStatus
+		LocationID string
+		Page       int
+		Size       int
+	}
+
+	// VariantStockResponse is the stock of one SKU summed over all locations and per location
+	VariantStockResponse struct {
+		VariantID string               `json:"variant_id"`
+		SKU       string               `json:"sku"`
+		OnHand    int                  `json:"on_hand"`
+		Reserved  int                  `json:"reserved"`
+		Available int                  `json:"available"`
+		Locations []*entity.StockLevel `json:"locations"`
+	}
+)
@@ -1,7 +1,12 @@
 package json
 
 import (
+	"archive/zip"
+	"context"
+	stdjson "encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -18,13 +23,12 @@ type (
 		Code    string      `json:"responseCode"`
 		Message string      `json:"message,omitempty"`
 		Data    interface{} `json:"data,omitempty"`
-		Paging  *paging     `json:"paging,omitempty"`
+		Paging  *Paging     `json:"paging,omitempty"`
 	}
 
 	jsonErrorResponse struct {
 		Code    string `json:"responseCode"`
 		Message string `json:"message"`
-		Error   string `json:"error,omitempty"`
 	}
 
 	ValidationField struct {
@@ -38,9 +42,24 @@ type (
 		ErrorDescription []ValidationField `json:"errorDescription,omitempty"`
 	}
 
-	paging struct {
-		Page      int `json:"page"`
-		TotalData int `json:"size"`
+	oauthErrorResponse struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+
+	// ArchiveFile is one JSON document inside a zip download
+	ArchiveFile struct {
+		Name string
+		Data interface{}
+	}
+
+	// Paging describes one page of a listing, TotalData always counts the filtered rows
+	Paging struct {
+		Page       int    `json:"page,omitempty"`
+		Size       int    `json:"size"`
+		TotalData  int    `json:"total_data"`
+		TotalPages int    `json:"total_pages"`
+		NextCursor string `json:"next_cursor,omitempty"`
 	}
 )
 
@@ -52,15 +71,28 @@ func NewResponseSuccess(c *gin.Context, result interface{}, message, serviceCode
 	})
 }
 
-func NewResponseSuccessPage(c *gin.Context, result interface{}, page, count int, message, serviceCode, responseCode string) {
+// NewPaging builds the paging object, page is left out for cursor pagination
+func NewPaging(page, size, totalData int, nextCursor string) *Paging {
+	totalPages := 0
+	if size > 0 {
+		totalPages = (totalData + size - 1) / size
+	}
+
+	return &Paging{
+		Page:       page,
+		Size:       size,
+		TotalData:  totalData,
+		TotalPages: totalPages,
+		NextCursor: nextCursor,
+	}
+}
+
+func NewResponseSuccessPage(c *gin.Context, result interface{}, paging *Paging, message, serviceCode, responseCode string) {
 	c.JSON(http.StatusOK, jsonResponsePage{
 		Code:    "200" + serviceCode + responseCode,
 		Message: message,
 		Data:    result,
-		Paging: &paging{ // Note the use of address to pass it as a pointer
-			Page:      page,
-			TotalData: count,
-		},
+		Paging:  paging,
 	})
 }
 
@@ -72,12 +104,91 @@ func NewResponseBadRequest(c *gin.Context, validationField []ValidationField, me
 	})
 }
 
+// NewResponseError logs err and answers with a generic message, internal details never reach the client
 func NewResponseError(c *gin.Context, err, serviceCode, errorCode string) {
 	log.Error().Msg(err)
 	c.JSON(http.StatusInternalServerError, jsonErrorResponse{
 		Code:    "500" + serviceCode + errorCode,
 		Message: "internal server error",
-		Error:   err,
+	})
+}
+
+// ServiceCodeKey is the context key AbortWithError leaves the service code under for the error middleware
+const ServiceCodeKey = "serviceCode"
+
+// AbortWithError hands err to the error middleware, which writes the response
+func AbortWithError(c *gin.Context, err error, serviceCode string) {
+	c.Set(ServiceCodeKey, serviceCode)
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// StatusClientClosedRequest is the nginx style status for a client that went away before the response
+const StatusClientClosedRequest = 499
+
+// NewResponseErrorFromCause answers 499 when the client disconnected, 504 when the request deadline passed and 500 otherwise.
+// The cause is logged on every path, a canceled query often hides the real failure behind the cancellation.
+func NewResponseErrorFromCause(c *gin.Context, err error, serviceCode, errorCode string) {
+	ctxErr := c.Request.Context().Err()
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(ctxErr, context.Canceled):
+		log.Warn().Msg("request canceled by client: " + err.Error())
+		NewResponseClientClosed(c, serviceCode, errorCode)
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctxErr, context.DeadlineExceeded):
+		log.Warn().Msg("request deadline exceeded: " + err.Error())
+		NewResponseGatewayTimeout(c, serviceCode, errorCode)
+	default:
+		NewResponseError(c, err.Error(), serviceCode, errorCode)
+	}
+}
+
+func NewResponseClientClosed(c *gin.Context, serviceCode, errorCode string) {
+	c.JSON(StatusClientClosedRequest, jsonResponse{
+		Code:    "499" + serviceCode + errorCode,
+		Message: "request canceled",
+	})
+}
+
+func NewResponseGatewayTimeout(c *gin.Context, serviceCode, errorCode string) {
+	c.JSON(http.StatusGatewayTimeout, jsonResponse{
+		Code:    "504" + serviceCode + errorCode,
+		Message: "request timeout",
+	})
+}
+
+func NewResponseServiceUnavailable(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusServiceUnavailable, jsonResponse{
+		Code:    "503" + serviceCode + errorCode,
+		Message: message,
+	})
+}
+
+// NewResponseStatus answers with any status using the service envelope
+func NewResponseStatus(c *gin.Context, status int, message, serviceCode, errorCode string) {
+	c.JSON(status, jsonResponse{
+		Code:    strconv.Itoa(status) + serviceCode + errorCode,
+		Message: message,
+	})
+}
+
+func NewResponseConflict(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusConflict, jsonResponse{
+		Code:    "409" + serviceCode + errorCode,
+		Message: message,
+	})
+}
+
+func NewResponseNotFound(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusNotFound, jsonResponse{
+		Code:    "404" + serviceCode + errorCode,
+		Message: message,
+	})
+}
+
+func NewResponseTooManyRequests(c *gin.Context, message, serviceCode, errorCode string) {
+	c.JSON(http.StatusTooManyRequests, jsonResponse{
+		Code:    "429" + serviceCode + errorCode,
+		Message: message,
 	})
 }
 
@@ -94,3 +205,52 @@ func NewResponseUnauthorized(c *gin.Context, message, serviceCode, errorCode str
 		Message: message,
 	})
 }
+
+// NewResponseOAuthToken answers an RFC 6749 token request, token responses must never be cached
+func NewResponseOAuthToken(c *gin.Context, result interface{}) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, result)
+}
+
+// NewResponseOAuthError answers with the RFC 6749 error shape instead of the service envelope
+func NewResponseOAuthError(c *gin.Context, status int, errorCode, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, oauthErrorResponse{
+		Error:            errorCode,
+		ErrorDescription: description,
+	})
+}
+
+// NewResponseAttachment sends result as a downloadable JSON file outside the service envelope
+func NewResponseAttachment(c *gin.Context, filename string, result interface{}) {
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.IndentedJSON(http.StatusOK, result)
+}
+
+// NewResponseArchive streams the files as indented JSON documents inside a zip download
+func NewResponseArchive(c *gin.Context, filename string, files []ArchiveFile) {
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for _, file := range files {
+		w, err := archive.Create(file.Name)
+		if err != nil {
+			log.Error().Msg("NewResponseArchive.Create.err : " + err.Error())
+			return
+		}
+
+		encoder := stdjson.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.Data); err != nil {
+			log.Error().Msg("NewResponseArchive.Encode.err : " + err.Error())
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Error().Msg("NewResponseArchive.Close.err : " + err.Error())
+	}
+}
@@ -0,0 +1,23 @@
+package locationDto
+
+type (
+	// LocationRequest creates or replaces a location, the code is stored in upper case
+	LocationRequest struct {
+		Code         string                `json:"code" binding:"required,max=32"`
+		Name         string                `json:"name" binding:"required,max=255"`
+		Type         string                `json:"type" binding:"required,oneof=store warehouse"`
+		AddressLine  string                `json:"address_line" binding:"max=1000"`
+		City         string                `json:"city" binding:"max=128"`
+		Province     string                `json:"province" binding:"max=128"`
+		PostalCode   string                `json:"postal_code" binding:"max=16"`
+		Phone        string                `json:"phone" binding:"max=32"`
+		OpeningHours []OpeningHoursRequest `json:"opening_hours" binding:"omitempty,max=21,dive"`
+		Active       *bool                 `json:"active"`
+	}
+
+	OpeningHoursRequest struct {
+		Day   string `json:"day" binding:"required,oneof=mon tue wed thu fri sat sun"`
+		Open  string `json:"open" binding:"required,TimeOfDay"`
+		Close string `json:"close" binding:"required,TimeOfDay"`
+	}
+)
@@ -0,0 +1,59 @@
+package orderDto
+
+import (
+	"clean-architecture/model/entity"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader must be sent with every new order, a retry with the same key returns the first order
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	MaxIdempotencyKeyLength = 128
+	DefaultPageSize         = 20
+	MaxPageSize             = 100
+)
+
+type (
+	// CreateOrderRequest checks out the given items, or the cart of the user when Items is empty.
+	// A pickup order needs PickupLocationID, a delivery needs ShippingAddress.
+	CreateOrderRequest struct {
+		Items            []OrderItemRequest      `json:"items" binding:"omitempty,max=100,dive"`
+		FulfilmentMethod string                  `json:"fulfilment_method" binding:"required,oneof=delivery pickup"`
+		PickupLocationID string                  `json:"pickup_location_id" binding:"omitempty,uuid"`
+		ShippingAddress  *ShippingAddressRequest `json:"shipping_address"`
+	}
+
+	OrderItemRequest struct {
+		VariantID string `json:"variant_id" binding:"required,uuid"`
+		Quantity  int    `json:"quantity" binding:"required,min=1,max=99"`
+	}
+
+	ShippingAddressRequest struct {
+		RecipientName string `json:"recipient_name" binding:"required,max=255"`
+		Phone         string `json:"phone" binding:"required,max=32"`
+		AddressLine   string `json:"address_line" binding:"required,max=1000"`
+		City          string `json:"city" binding:"required,max=128"`
+		Province      string `json:"province" binding:"required,max=128"`
+		PostalCode    string `json:"postal_code" binding:"required,len=5,number"`
+		Note          string `json:"note" binding:"max=500"`
+	}
+
+	// CheckoutResponse prices an order without placing it, its stock is held for the customer until HeldUntil
+	CheckoutResponse struct {
+		FulfilmentMethod entity.FulfilmentMethod `json:"fulfilment_method"`
+		LocationID       string                  `json:"location_id"`
+		Items            []*entity.OrderItem     `json:"items"`
+		Subtotal         int64                   `json:"subtotal"`
+		Discount         int64                   `json:"discount"`
+		ShippingFee      int64                   `json:"shipping_fee"`
+		Tax              int64                   `json:"tax"`
+		Total            int64                   `json:"total"`
+		HeldUntil        time.Time               `json:"held_until"`
+	}
+
+	OrderListQuery struct {
+		UserID string
+		Page   int
+		Size   int
+	}
+)
@@ -0,0 +1,46 @@
+package productDto
+
+import "clean-architecture/model/entity"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type (
+	// BrandRequest creates or replaces a brand, the slug is derived from the name when left out
+	BrandRequest struct {
+		Name        string `json:"name" binding:"required,max=255"`
+		Slug        string `json:"slug" binding:"omitempty,max=255,Slug"`
+		Description string `json:"description"`
+	}
+
+	// CategoryRequest creates or replaces a category, a nil parent makes it a root category
+	CategoryRequest struct {
+		ParentID    *string `json:"parent_id" binding:"omitempty,uuid"`
+		Name        string  `json:"name" binding:"required,max=255"`
+		Slug        string  `json:"slug" binding:"omitempty,max=255,Slug"`
+		Description string  `json:"description"`
+	}
+
+	// ProductRequest creates or replaces a product, new products start as draft unless a status is given
+	ProductRequest struct {
+		BrandID     *string `json:"brand_id" binding:"omitempty,uuid"`
+		CategoryID  *string `json:"category_id" binding:"omitempty,uuid"`
+		Name        string  `json:"name" binding:"required,max=255"`
+		Slug        string  `json:"slug" binding:"omitempty,max=255,Slug"`
+		Description string  `json:"description"`
+		Status      string  `json:"status" binding:"omitempty,oneof=draft active archived"`
+		Price       *int64  `json:"price" binding:"required,min=0"`
+	}
+
+	// ProductListQuery filters the product listing, CategorySlug also matches every subcategory
+	ProductListQuery struct {
+		Search       string
+		BrandSlug    string
+		CategorySlug string
+		Status       entity.ProductStatus
+		Page         int
+		Size         int
+	}
+)
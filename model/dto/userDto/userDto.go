@@ -1,15 +1,59 @@
 package userDto
 
+import (
+	"clean-architecture/model/entity"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// UserSortFields are the only columns the user listing may be sorted by
+var UserSortFields = []string{"created_at", "fullname", "email"}
+
 type (
+	// UserListQuery filters, sorts and pages the user listing. Cursor takes precedence over Page.
+	UserListQuery struct {
+		Email       string
+		FullName    string
+		Role        string
+		CreatedFrom *time.Time
+		// CreatedTo is exclusive
+		CreatedTo  *time.Time
+		SortBy     string
+		Descending bool
+		Page       int
+		Size       int
+		Cursor     string
+	}
+
+	// UserCursor is the keyset position the next page starts after, it is sent to clients base64 encoded
+	UserCursor struct {
+		SortBy     string `json:"s"`
+		Descending bool   `json:"d"`
+		Value      string `json:"v"`
+		ID         string `json:"i"`
+	}
+
+	UserPage struct {
+		Users      []*entity.User
+		Total      int
+		NextCursor string
+	}
+
 	LoginUserRequest struct {
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
 	}
 
 	CreateUserRequest struct {
-		Email    string `json:"email" binding:"required,email"`
-		FullName string `json:"fullname" binding:"required"`
-		Password string `json:"password" binding:"required,min=8,max=20"`
+		Email        string `json:"email" binding:"required,email"`
+		FullName     string `json:"fullname" binding:"required"`
+		Password     string `json:"password" binding:"required,min=8,max=20"`
+		DateOfBirth  string `json:"date_of_birth" binding:"required,DateOnly"`
+		Jurisdiction string `json:"jurisdiction" binding:"omitempty,len=2"`
 	}
 
 	UpdateUserRequest struct {
@@ -17,4 +61,88 @@ type (
 		FullName string `json:"fullname" binding:"required"`
 		Password string `json:"password" binding:"required,min=8,max=20"`
 	}
+
+	// PatchUserRequest follows JSON merge-patch, only the fields present in the body are changed
+	PatchUserRequest struct {
+		FullName *string `json:"fullname" binding:"omitempty,min=1"`
+		Password *string `json:"password" binding:"omitempty,min=8,max=20"`
+	}
+
+	ChangePasswordRequest struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8,max=20"`
+	}
+
+	DeleteAccountRequest struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	RejectPrivacyRequest struct {
+		Note string `json:"note" binding:"required"`
+	}
+
+	UpdateUserRoleRequest struct {
+		Role string `json:"role" binding:"required,oneof=customer cashier store_manager admin"`
+	}
+
+	SubmitAgeDocumentRequest struct {
+		DocumentReference string `json:"document_reference" binding:"required"`
+	}
+
+	ReviewAgeVerificationRequest struct {
+		Status string `json:"status" binding:"required,oneof=verified rejected manual_review"`
+		Note   string `json:"note"`
+	}
+
+	VerifyEmailRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	ResendVerificationRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	ForgotPasswordRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	ResetPasswordRequest struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=8,max=20"`
+	}
+
+	LoginMfaRequest struct {
+		MfaToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	MfaCodeRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	MfaChallengeResponse struct {
+		MfaRequired bool   `json:"mfa_required"`
+		MfaToken    string `json:"mfa_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	MfaEnrollResponse struct {
+		Secret     string `json:"secret"`
+		OtpauthURI string `json:"otpauth_uri"`
+	}
+
+	MfaRecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	TokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
 )
@@ -0,0 +1,66 @@
+package userDto
+
+import (
+	"clean-architecture/model/entity"
+	"time"
+)
+
+type (
+	// UserResponse is the public view of a user, what staff who can look users up but not manage them see
+	UserResponse struct {
+		ID                    string                       `json:"id"`
+		FullName              string                       `json:"fullname"`
+		Email                 string                       `json:"email"`
+		Role                  entity.Role                  `json:"role"`
+		AgeVerificationStatus entity.AgeVerificationStatus `json:"age_verification_status"`
+		CreatedAt             time.Time                    `json:"created_at"`
+	}
+
+	// UserDetailResponse adds the personal and account security fields, shown to the user themself and to user managers
+	UserDetailResponse struct {
+		UserResponse
+		DateOfBirth     *time.Time `json:"date_of_birth"`
+		Jurisdiction    string     `json:"jurisdiction"`
+		EmailVerifiedAt *time.Time `json:"email_verified_at"`
+		MfaEnabledAt    *time.Time `json:"mfa_enabled_at"`
+		DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	}
+)
+
+func NewUserResponse(u *entity.User) *UserResponse {
+	return &UserResponse{
+		ID:                    u.ID,
+		FullName:              u.FullName,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		AgeVerificationStatus: u.AgeVerificationStatus,
+		CreatedAt:             u.CreatedAt,
+	}
+}
+
+func NewUserDetailResponse(u *entity.User) *UserDetailResponse {
+	return &UserDetailResponse{
+		UserResponse:    *NewUserResponse(u),
+		DateOfBirth:     u.DateOfBirth,
+		Jurisdiction:    u.Jurisdiction,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		MfaEnabledAt:    u.MfaEnabledAt,
+		DeletedAt:       u.DeletedAt,
+	}
+}
+
+// NewUserView returns the detail view to the owner and to actors who may write users, the public view to everyone else
+func NewUserView(actor entity.Actor, u *entity.User) interface{} {
+	if actor.CanManage(u.ID, entity.PermissionUserWrite) {
+		return NewUserDetailResponse(u)
+	}
+	return NewUserResponse(u)
+}
+
+func NewUserViews(actor entity.Actor, users []*entity.User) []interface{} {
+	views := make([]interface{}, len(users))
+	for i, u := range users {
+		views[i] = NewUserView(actor, u)
+	}
+	return views
+}
@@ -0,0 +1,52 @@
+package variantDto
+
+import "clean-architecture/model/entity"
+
+const (
+	// MaxMatrixSize bounds how many variants one generate call may create
+	MaxMatrixSize = 500
+	// MaxSKULength is the width of the sku column, generated SKUs must fit it too
+	MaxSKULength = 64
+)
+
+type (
+	// SetOptionsRequest replaces the option axes of a product, their order is the display order
+	SetOptionsRequest struct {
+		Options []OptionRequest `json:"options" binding:"omitempty,dive"`
+	}
+
+	OptionRequest struct {
+		Name   string   `json:"name" binding:"required,max=64"`
+		Values []string `json:"values" binding:"required,min=1,dive,required,max=64"`
+	}
+
+	// CreateVariantRequest adds one combination, the price defaults to the product price
+	CreateVariantRequest struct {
+		SKU     string            `json:"sku" binding:"required,max=64"`
+		Barcode *string           `json:"barcode" binding:"omitempty,max=64"`
+		Price   *int64            `json:"price" binding:"omitempty,min=0"`
+		Active  *bool             `json:"active"`
+		Options map[string]string `json:"options"`
+	}
+
+	// UpdateVariantRequest replaces the sellable fields, the option combination of a variant never changes
+	UpdateVariantRequest struct {
+		SKU     string  `json:"sku" binding:"required,max=64"`
+		Barcode *string `json:"barcode" binding:"omitempty,max=64"`
+		Price   *int64  `json:"price" binding:"required,min=0"`
+		Active  *bool   `json:"active" binding:"required"`
+	}
+
+	// GenerateVariantsRequest creates every missing combination of the product options.
+	// SKUs are the prefix followed by the option values, for example LIQ-MANGO-3MG-30ML.
+	GenerateVariantsRequest struct {
+		SKUPrefix string `json:"sku_prefix" binding:"required,max=24"`
+		Price     *int64 `json:"price" binding:"omitempty,min=0"`
+		Active    bool   `json:"active"`
+	}
+
+	ProductVariantsResponse struct {
+		Options  []*entity.ProductOption `json:"options"`
+		Variants []*entity.Variant       `json:"variants"`
+	}
+)
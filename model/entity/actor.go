@@ -0,0 +1,18 @@
+package entity
+
+// Actor is the authenticated caller a use case acts on behalf of
+type Actor struct {
+	ID          string
+	Role        Role
+	MfaRequired bool
+}
+
+// Can reports whether the actor holds the permission, a pending mfa enrollment grants none
+func (a Actor) Can(permission Permission) bool {
+	return !a.MfaRequired && a.Role.HasPermission(permission)
+}
+
+// CanManage reports whether the actor owns the account or holds the permission over other accounts
+func (a Actor) CanManage(ownerID string, permission Permission) bool {
+	return a.ID == ownerID || a.Can(permission)
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type (
+	// Cart belongs to a user, or to a guest when UserID is nil
+	Cart struct {
+		ID        string    `json:"id"`
+		UserID    *string   `json:"user_id"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// CartItem is one line of a cart together with the current catalog data of its variant.
+	// UnitPrice is the price the customer last saw, CurrentPrice what the variant costs now.
+	CartItem struct {
+		VariantID    string            `json:"variant_id"`
+		ProductID    string            `json:"product_id"`
+		ProductName  string            `json:"product_name"`
+		ProductSlug  string            `json:"product_slug"`
+		SKU          string            `json:"sku"`
+		Options      map[string]string `json:"options"`
+		Quantity     int               `json:"quantity"`
+		UnitPrice    int64             `json:"unit_price"`
+		CurrentPrice int64             `json:"current_price"`
+		// Purchasable is false once the variant is deactivated or its product is no longer active
+		Purchasable bool      `json:"purchasable"`
+		AddedAt     time.Time `json:"added_at"`
+	}
+)
@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+type (
+	// Client is a registered api consumer such as the web shop, the POS or a partner integration
+	Client struct {
+		ID         string     `json:"id"`
+		ClientID   string     `json:"client_id"`
+		Name       string     `json:"name"`
+		SecretHash string     `json:"-"`
+		Scopes     []string   `json:"scopes"`
+		GrantTypes []string   `json:"grant_types"`
+		CreatedAt  time.Time  `json:"created_at"`
+		UpdatedAt  time.Time  `json:"updated_at"`
+		RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	}
+)
+
+const (
+	GrantTypePassword          = "password"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+func (c *Client) AllowsGrant(grantType string) bool {
+	for _, allowed := range c.GrantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) AllowsScope(scope string) bool {
+	for _, allowed := range c.Scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package entity
+
+import "time"
+
+type (
+	MovementType      string
+	AdjustmentReason  string
+	ReservationStatus string
+	TransferStatus    string
+
+	// StockMovement is one immutable ledger entry, Quantity is positive when stock comes in and negative when it leaves
+	StockMovement struct {
+		ID         string           `json:"id"`
+		LocationID string           `json:"location_id"`
+		VariantID  string           `json:"variant_id"`
+		Type       MovementType     `json:"type"`
+		Quantity   int              `json:"quantity"`
+		ReasonCode AdjustmentReason `json:"reason_code,omitempty"`
+		Reference  string           `json:"reference,omitempty"`
+		Note       string           `json:"note,omitempty"`
+		CreatedBy  *string          `json:"created_by"`
+		CreatedAt  time.Time        `json:"created_at"`
+	}
+
+	// StockLevel is the ledger total of a variant at one location, Available is what can still be reserved or sold
+	StockLevel struct {
+		LocationID   string    `json:"location_id"`
+		LocationCode string    `json:"location_code"`
+		VariantID    string    `json:"variant_id"`
+		SKU          string    `json:"sku"`
+		OnHand       int       `json:"on_hand"`
+		Reserved     int       `json:"reserved"`
+		Available    int       `json:"available"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	// StockAllocation takes the lines out of one location as sales, all of them or none.
+	// HoldReference names the reservations of the checkout the allocation completes, if any.
+	StockAllocation struct {
+		LocationID    string
+		Reference     string
+		HoldReference string
+		CreatedBy     *string
+		Lines         []StockLine
+	}
+
+	StockLine struct {
+		VariantID string
+		Quantity  int
+	}
+
+	// Reservation holds stock of a variant at a location for a checkout until it is consumed, released or expires
+	Reservation struct {
+		ID         string            `json:"id"`
+		LocationID string            `json:"location_id"`
+		VariantID  string            `json:"variant_id"`
+		Quantity   int               `json:"quantity"`
+		Reference  string            `json:"reference"`
+		Status     ReservationStatus `json:"status"`
+		ExpiresAt  time.Time         `json:"expires_at"`
+		CreatedAt  time.Time         `json:"created_at"`
+	}
+
+	// StockTransfer moves stock between two locations, it leaves the source when shipped and
+	// arrives at the destination when received
+	StockTransfer struct {
+		ID             string          `json:"id"`
+		FromLocationID string          `json:"from_location_id"`
+		ToLocationID   string          `json:"to_location_id"`
+		Status         TransferStatus  `json:"status"`
+		Note           string          `json:"note"`
+		HasDiscrepancy bool            `json:"has_discrepancy"`
+		Lines          []*TransferLine `json:"lines"`
+		RequestedBy    *string         `json:"requested_by"`
+		ShippedBy      *string         `json:"shipped_by"`
+		ReceivedBy     *string         `json:"received_by"`
+		CreatedAt      time.Time       `json:"created_at"`
+		ShippedAt      *time.Time      `json:"shipped_at"`
+		ReceivedAt     *time.Time      `json:"received_at"`
+		UpdatedAt      time.Time       `json:"updated_at"`
+	}
+
+	// TransferLine is one variant of a transfer, Discrepancy is what was shipped but did not arrive
+	TransferLine struct {
+		VariantID         string `json:"variant_id"`
+		SKU               string `json:"sku"`
+		QuantityRequested int    `json:"quantity_requested"`
+		QuantityShipped   *int   `json:"quantity_shipped"`
+		QuantityReceived  *int   `json:"quantity_received"`
+		Discrepancy       int    `json:"discrepancy"`
+		DiscrepancyNote   string `json:"discrepancy_note,omitempty"`
+	}
+)
+
+const (
+	MovementReceive    MovementType = "receive"
+	MovementSale       MovementType = "sale"
+	MovementAdjustment MovementType = "adjustment"
+	MovementReturn     MovementType = "return"
+	MovementTransfer   MovementType = "transfer"
+)
+
+const (
+	ReasonCountCorrection AdjustmentReason = "count_correction"
+	ReasonDamaged         AdjustmentReason = "damaged"
+	ReasonExpired         AdjustmentReason = "expired"
+	ReasonLost            AdjustmentReason = "lost"
+	ReasonFound           AdjustmentReason = "found"
+	ReasonOther           AdjustmentReason = "other"
+)
+
+const (
+	ReservationActive   ReservationStatus = "active"
+	ReservationReleased ReservationStatus = "released"
+	ReservationConsumed ReservationStatus = "consumed"
+	ReservationExpired  ReservationStatus = "expired"
+)
+
+const (
+	TransferRequested TransferStatus = "requested"
+	TransferInTransit TransferStatus = "in_transit"
+	TransferReceived  TransferStatus = "received"
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+func (s TransferStatus) IsValid() bool {
+	switch s {
+	case TransferRequested, TransferInTransit, TransferReceived, TransferCancelled:
+		return true
+	}
+	return false
+}
@@ -1,11 +1,39 @@
 package entity
 
-import "github.com/dgrijalva/jwt-go"
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	TokenPurposeAccess            = "access"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMfaPending        = "mfa_pending"
+	TokenPurposeCart              = "cart"
+)
 
 type (
 	JwtClaim struct {
 		jwt.StandardClaims
-		ID    string `json:"id"`
-		Roles string `json:"role"`
+		ID       string `json:"id"`
+		Roles    string `json:"role"`
+		FamilyID string `json:"fid,omitempty"`
+		Purpose  string `json:"pur"`
+		// MfaRequired marks a session whose role needs a totp login before its permissions apply
+		MfaRequired bool   `json:"mfa_required,omitempty"`
+		ClientID    string `json:"cid,omitempty"`
+		Scope       string `json:"scope,omitempty"`
+	}
+
+	RefreshToken struct {
+		ID        string
+		FamilyID  string
+		UserID    string
+		TokenHash string
+		ExpiresAt time.Time
+		UsedAt    *time.Time
+		// MfaAuthenticated is inherited from the login that started the family
+		MfaAuthenticated bool
 	}
 )
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+type (
+	LocationType string
+
+	// Location is a place that holds stock, either an outlet customers can visit or a warehouse
+	Location struct {
+		ID           string         `json:"id"`
+		Code         string         `json:"code"`
+		Name         string         `json:"name"`
+		Type         LocationType   `json:"type"`
+		AddressLine  string         `json:"address_line"`
+		City         string         `json:"city"`
+		Province     string         `json:"province"`
+		PostalCode   string         `json:"postal_code"`
+		Phone        string         `json:"phone"`
+		OpeningHours []OpeningHours `json:"opening_hours"`
+		Active       bool           `json:"active"`
+		CreatedAt    time.Time      `json:"created_at"`
+		UpdatedAt    time.Time      `json:"updated_at"`
+	}
+
+	// OpeningHours is one opening window in local time, a Close before Open runs past midnight
+	OpeningHours struct {
+		Day   string `json:"day"`
+		Open  string `json:"open"`
+		Close string `json:"close"`
+	}
+)
+
+const (
+	LocationTypeStore     LocationType = "store"
+	LocationTypeWarehouse LocationType = "warehouse"
+)
+
+func (t LocationType) IsValid() bool {
+	switch t {
+	case LocationTypeStore, LocationTypeWarehouse:
+		return true
+	}
+	return false
+}
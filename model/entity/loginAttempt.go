@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+type (
+	// LoginAttempt counts recent failed logins for a key such as an account email or a client ip
+	LoginAttempt struct {
+		Key           string
+		Failures      int
+		LastFailureAt *time.Time
+		LockedUntil   *time.Time
+	}
+)
+
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
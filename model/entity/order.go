@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+type (
+	OrderStatus      string
+	FulfilmentMethod string
+
+	// Order is a placed purchase. Amounts are whole rupiah, Total is Subtotal - Discount + ShippingFee + Tax.
+	// LocationID is where the stock was taken from, the online location for deliveries or the store of a pickup.
+	Order struct {
+		ID               string           `json:"id"`
+		UserID           string           `json:"user_id"`
+		Status           OrderStatus      `json:"status"`
+		FulfilmentMethod FulfilmentMethod `json:"fulfilment_method"`
+		LocationID       string           `json:"location_id"`
+		ShippingAddress  *ShippingAddress `json:"shipping_address"`
+		Items            []*OrderItem     `json:"items"`
+		Subtotal         int64            `json:"subtotal"`
+		Discount         int64            `json:"discount"`
+		ShippingFee      int64            `json:"shipping_fee"`
+		Tax              int64            `json:"tax"`
+		Total            int64            `json:"total"`
+		IdempotencyKey   string           `json:"-"`
+		RequestHash      string           `json:"-"`
+		CreatedAt        time.Time        `json:"created_at"`
+		UpdatedAt        time.Time        `json:"updated_at"`
+	}
+
+	// OrderItem keeps the name, SKU and price of a variant as they were when the order was placed
+	OrderItem struct {
+		VariantID   string            `json:"variant_id"`
+		ProductID   string            `json:"product_id"`
+		ProductName string            `json:"product_name"`
+		SKU         string            `json:"sku"`
+		Options     map[string]string `json:"options"`
+		Quantity    int               `json:"quantity"`
+		UnitPrice   int64             `json:"unit_price"`
+		LineTotal   int64             `json:"line_total"`
+	}
+
+	ShippingAddress struct {
+		RecipientName string `json:"recipient_name"`
+		Phone         string `json:"phone"`
+		AddressLine   string `json:"address_line"`
+		City          string `json:"city"`
+		Province      string `json:"province"`
+		PostalCode    string `json:"postal_code"`
+		Note          string `json:"note,omitempty"`
+	}
+)
+
+const (
+	OrderStatusPlaced OrderStatus = "placed"
+
+	FulfilmentDelivery FulfilmentMethod = "delivery"
+	FulfilmentPickup   FulfilmentMethod = "pickup"
+)
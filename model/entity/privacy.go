@@ -0,0 +1,101 @@
+package entity
+
+import "time"
+
+type (
+	PrivacyRequestType   string
+	PrivacyRequestStatus string
+
+	// PrivacyRequest logs a data subject request under the PDP law and how it was handled
+	PrivacyRequest struct {
+		ID          string               `json:"id"`
+		UserID      string               `json:"user_id"`
+		Type        PrivacyRequestType   `json:"type"`
+		Status      PrivacyRequestStatus `json:"status"`
+		RequestedBy string               `json:"requested_by"`
+		HandledBy   *string              `json:"handled_by"`
+		Note        string               `json:"note"`
+		CreatedAt   time.Time            `json:"created_at"`
+		CompletedAt *time.Time           `json:"completed_at"`
+	}
+
+	// DataExport is everything stored about one user, secrets and token hashes are never part of it
+	DataExport struct {
+		ExportedAt       time.Time              `json:"exported_at"`
+		Profile          DataExportProfile      `json:"profile"`
+		AgeVerifications []DataExportAgeCheck   `json:"age_verifications"`
+		Sessions         []DataExportSession    `json:"sessions"`
+		PasswordResets   []DataExportReset      `json:"password_resets"`
+		Changes          []DataExportChange     `json:"changes"`
+		Orders           []*Order               `json:"orders"`
+		Cart             []DataExportCartItem   `json:"cart"`
+		PrivacyRequests  []*PrivacyRequest      `json:"privacy_requests"`
+		LoginAttempts    *DataExportLoginRecord `json:"login_attempts"`
+	}
+
+	DataExportProfile struct {
+		ID                    string                `json:"id"`
+		FullName              string                `json:"fullname"`
+		Email                 string                `json:"email"`
+		Role                  Role                  `json:"role"`
+		DateOfBirth           *time.Time            `json:"date_of_birth"`
+		Jurisdiction          string                `json:"jurisdiction"`
+		AgeVerificationStatus AgeVerificationStatus `json:"age_verification_status"`
+		EmailVerifiedAt       *time.Time            `json:"email_verified_at"`
+		MfaEnabledAt          *time.Time            `json:"mfa_enabled_at"`
+		CreatedAt             time.Time             `json:"created_at"`
+		UpdatedAt             time.Time             `json:"updated_at"`
+	}
+
+	DataExportAgeCheck struct {
+		Method            string                `json:"method"`
+		DocumentReference string                `json:"document_reference"`
+		Status            AgeVerificationStatus `json:"status"`
+		Note              string                `json:"note"`
+		CreatedAt         time.Time             `json:"created_at"`
+	}
+
+	DataExportSession struct {
+		CreatedAt        time.Time  `json:"created_at"`
+		RevokedAt        *time.Time `json:"revoked_at"`
+		MfaAuthenticated bool       `json:"mfa_authenticated"`
+	}
+
+	DataExportReset struct {
+		CreatedAt time.Time  `json:"created_at"`
+		ExpiresAt time.Time  `json:"expires_at"`
+		UsedAt    *time.Time `json:"used_at"`
+	}
+
+	DataExportChange struct {
+		ChangedBy *string   `json:"changed_by"`
+		Fields    []string  `json:"fields"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	DataExportCartItem struct {
+		VariantID   string    `json:"variant_id"`
+		SKU         string    `json:"sku"`
+		ProductName string    `json:"product_name"`
+		Quantity    int       `json:"quantity"`
+		AddedAt     time.Time `json:"added_at"`
+	}
+
+	DataExportLoginRecord struct {
+		Failures      int        `json:"failures"`
+		LastFailureAt *time.Time `json:"last_failure_at"`
+		LockedUntil   *time.Time `json:"locked_until"`
+	}
+)
+
+const (
+	PrivacyRequestExport  PrivacyRequestType = "export"
+	PrivacyRequestErasure PrivacyRequestType = "erasure"
+)
+
+const (
+	PrivacyRequestPending   PrivacyRequestStatus = "pending"
+	PrivacyRequestCompleted PrivacyRequestStatus = "completed"
+	PrivacyRequestRejected  PrivacyRequestStatus = "rejected"
+	PrivacyRequestFailed    PrivacyRequestStatus = "failed"
+)
@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+type (
+	ProductStatus string
+
+	Brand struct {
+		ID          string    `json:"id"`
+		Name        string    `json:"name"`
+		Slug        string    `json:"slug"`
+		Description string    `json:"description"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	// Category is one node of the category tree, Children is only filled when the tree is built
+	Category struct {
+		ID          string      `json:"id"`
+		ParentID    *string     `json:"parent_id"`
+		Name        string      `json:"name"`
+		Slug        string      `json:"slug"`
+		Description string      `json:"description"`
+		CreatedAt   time.Time   `json:"created_at"`
+		UpdatedAt   time.Time   `json:"updated_at"`
+		Children    []*Category `json:"children,omitempty"`
+	}
+
+	// Product is a catalog entry, Price is in whole rupiah
+	Product struct {
+		ID          string        `json:"id"`
+		BrandID     *string       `json:"brand_id"`
+		CategoryID  *string       `json:"category_id"`
+		Name        string        `json:"name"`
+		Slug        string        `json:"slug"`
+		Description string        `json:"description"`
+		Status      ProductStatus `json:"status"`
+		Price       int64         `json:"price"`
+		CreatedAt   time.Time     `json:"created_at"`
+		UpdatedAt   time.Time     `json:"updated_at"`
+	}
+)
+
+const (
+	ProductStatusDraft    ProductStatus = "draft"
+	ProductStatusActive   ProductStatus = "active"
+	ProductStatusArchived ProductStatus = "archived"
+)
+
+func (s ProductStatus) IsValid() bool {
+	switch s {
+	case ProductStatusDraft, ProductStatusActive, ProductStatusArchived:
+		return true
+	}
+	return false
+}
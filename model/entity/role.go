@@ -0,0 +1,96 @@
+package entity
+
+type (
+	Role       string
+	Permission string
+)
+
+const (
+	RoleCustomer     Role = "customer"
+	RoleCashier      Role = "cashier"
+	RoleStoreManager Role = "store_manager"
+	RoleAdmin        Role = "admin"
+)
+
+const (
+	PermissionUserRead   Permission = "users:read"
+	PermissionUserWrite  Permission = "users:write"
+	PermissionUserDelete Permission = "users:delete"
+	PermissionRoleAssign Permission = "roles:assign"
+	PermissionAgeVerify  Permission = "age:verify"
+
+	PermissionClientManage  Permission = "clients:manage"
+	PermissionPrivacyManage Permission = "privacy:manage"
+
+	PermissionCatalogManage Permission = "catalog:manage"
+
+	PermissionInventoryRead   Permission = "inventory:read"
+	PermissionInventoryManage Permission = "inventory:manage"
+
+	PermissionOrderRead Permission = "orders:read"
+)
+
+// RolePermissions is the permission matrix granted to each role
+var RolePermissions = map[Role][]Permission{
+	RoleCustomer: {},
+	RoleCashier: {
+		PermissionUserRead,
+		PermissionAgeVerify,
+		PermissionInventoryRead,
+		PermissionOrderRead,
+	},
+	RoleStoreManager: {
+		PermissionUserRead,
+		PermissionUserWrite,
+		PermissionAgeVerify,
+		PermissionCatalogManage,
+		PermissionInventoryRead,
+		PermissionInventoryManage,
+		PermissionOrderRead,
+	},
+	RoleAdmin: {
+		PermissionUserRead,
+		PermissionUserWrite,
+		PermissionUserDelete,
+		PermissionRoleAssign,
+		PermissionAgeVerify,
+		PermissionClientManage,
+		PermissionPrivacyManage,
+		PermissionCatalogManage,
+		PermissionInventoryRead,
+		PermissionInventoryManage,
+		PermissionOrderRead,
+	},
+}
+
+// roleRanks orders the roles by authority, accounts are only managed from a higher rank
+var roleRanks = map[Role]int{
+	RoleCustomer:     0,
+	RoleCashier:      1,
+	RoleStoreManager: 2,
+	RoleAdmin:        3,
+}
+
+// Outranks reports whether r holds more authority than other
+func (r Role) Outranks(other Role) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// IsStaff reports whether the role belongs to store staff or administrators rather than customers
+func (r Role) IsStaff() bool {
+	return r.IsValid() && r != RoleCustomer
+}
+
+func (r Role) IsValid() bool {
+	_, ok := RolePermissions[r]
+	return ok
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range RolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,58 @@
 package entity
 
+import (
+	"errors"
+	"time"
+)
+
 type (
+	AgeVerificationStatus string
+
+	// User is the stored account including its password hash and totp secret.
+	// It refuses to be serialized, responses are built from the userDto response types instead.
 	User struct {
-		ID       string `json:"id"`
-		FullName string `json:"fullname"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		ID                    string
+		FullName              string
+		Email                 string
+		Password              string
+		Role                  Role
+		DateOfBirth           *time.Time
+		Jurisdiction          string
+		AgeVerificationStatus AgeVerificationStatus
+		EmailVerifiedAt       *time.Time
+		MfaSecret             string
+		MfaEnabledAt          *time.Time
+		CreatedAt             time.Time
+		DeletedAt             *time.Time
+	}
+
+	AgeVerification struct {
+		UserID            string
+		Method            string
+		DocumentReference string
+		Status            AgeVerificationStatus
+		ReviewerID        string
+		Note              string
 	}
 )
+
+const (
+	AgeVerificationPending        AgeVerificationStatus = "pending"
+	AgeVerificationManualReview   AgeVerificationStatus = "manual_review"
+	AgeVerificationDocumentReview AgeVerificationStatus = "document_review"
+	AgeVerificationVerified       AgeVerificationStatus = "verified"
+	AgeVerificationRejected       AgeVerificationStatus = "rejected"
+)
+
+const (
+	AgeVerificationMethodDocument = "document"
+	AgeVerificationMethodManual   = "manual"
+)
+
+// ErrUserNotSerializable is returned when a User reaches a json encoder directly
+var ErrUserNotSerializable = errors.New("entity.User holds secrets and must be mapped to a userDto response before serializing")
+
+// MarshalJSON fails closed so a password hash or totp secret can never be written into a response by accident
+func (User) MarshalJSON() ([]byte, error) {
+	return nil, ErrUserNotSerializable
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// ProductOption is one axis a product varies on, such as flavor, nicotine strength or bottle size
+	ProductOption struct {
+		Name     string   `json:"name"`
+		Values   []string `json:"values"`
+		Position int      `json:"position"`
+	}
+
+	// Variant is one sellable combination of option values with its own SKU, Price is in whole rupiah
+	Variant struct {
+		ID        string            `json:"id"`
+		ProductID string            `json:"product_id"`
+		SKU       string            `json:"sku"`
+		Barcode   *string           `json:"barcode"`
+		Price     int64             `json:"price"`
+		Active    bool              `json:"active"`
+		Options   map[string]string `json:"options"`
+		CreatedAt time.Time         `json:"created_at"`
+		UpdatedAt time.Time         `json:"updated_at"`
+	}
+)
+
+// MatchOptions checks that values names exactly one listed value for every option, case insensitively,
+// and returns the values in the spelling of the option definitions
+func MatchOptions(options []*ProductOption, values map[string]string) (map[string]string, bool) {
+	if len(values) != len(options) {
+		return nil, false
+	}
+
+	matched := make(map[string]string, len(options))
+	for _, option := range options {
+		value, ok := lookupFold(values, option.Name)
+		if !ok {
+			return nil, false
+		}
+
+		found := false
+		for _, listed := range option.Values {
+			if strings.EqualFold(listed, value) {
+				matched[option.Name] = listed
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	return matched, true
+}
+
+func lookupFold(values map[string]string, name string) (string, bool) {
+	for key, value := range values {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// OptionKey is the canonical form of a combination, two variants of a product may not share one
+func OptionKey(values map[string]string) string {
+	pairs := make([][2]string, 0, len(values))
+	for name, value := range values {
+		pairs = append(pairs, [2]string{strings.ToLower(name), strings.ToLower(value)})
+	}
+	// names only differing in case fold to the same name, their values break the tie so the key stays stable
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	key, _ := json.Marshal(pairs)
+	return string(key)
+}
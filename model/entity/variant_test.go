@@ -0,0 +1,66 @@
+package entity
+
+import "testing"
+
+func TestOptionKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        map[string]string
+		b        map[string]string
+		wantSame bool
+	}{
+		{
+			name:     "same combination",
+			a:        map[string]string{"flavor": "mango", "nicotine": "3mg"},
+			b:        map[string]string{"flavor": "mango", "nicotine": "3mg"},
+			wantSame: true,
+		},
+		{
+			name:     "names and values in another case",
+			a:        map[string]string{"Flavor": "Mango", "Nicotine": "3MG"},
+			b:        map[string]string{"flavor": "mango", "nicotine": "3mg"},
+			wantSame: true,
+		},
+		{
+			name:     "another value",
+			a:        map[string]string{"flavor": "mango", "nicotine": "3mg"},
+			b:        map[string]string{"flavor": "mango", "nicotine": "6mg"},
+			wantSame: false,
+		},
+		{
+			name:     "values swapped between options",
+			a:        map[string]string{"size": "30", "strength": "60"},
+			b:        map[string]string{"size": "60", "strength": "30"},
+			wantSame: false,
+		},
+		{
+			name:     "separators inside a value",
+			a:        map[string]string{"flavor": "mango", "nicotine": "3mg"},
+			b:        map[string]string{"flavor": `mango"],["nicotine","3mg`},
+			wantSame: false,
+		},
+		{
+			name:     "names only differing in case",
+			a:        map[string]string{"flavor": "mango", "Flavor": "grape"},
+			b:        map[string]string{"Flavor": "mango", "flavor": "grape"},
+			wantSame: true,
+		},
+		{
+			name:     "empty combination",
+			a:        map[string]string{},
+			b:        nil,
+			wantSame: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// map iteration order varies, so a key that depends on it shows up over a few runs
+			for i := 0; i < 20; i++ {
+				if got := OptionKey(tt.a) == OptionKey(tt.b); got != tt.wantSame {
+					t.Fatalf("OptionKey(%v) == OptionKey(%v) is %v, want %v", tt.a, tt.b, got, tt.wantSame)
+				}
+			}
+		})
+	}
+}
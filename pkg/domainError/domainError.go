@@ -0,0 +1,72 @@
+package domainError
+
+import "errors"
+
+// Kind classifies a domain error, the error middleware maps each kind to one http status
+type Kind string
+
+const (
+	KindValidation      Kind = "validation"
+	KindUnauthorized    Kind = "unauthorized"
+	KindForbidden       Kind = "forbidden"
+	KindNotFound        Kind = "not_found"
+	KindConflict        Kind = "conflict"
+	KindTooManyRequests Kind = "too_many_requests"
+)
+
+type (
+	// Error is a failure that is safe to show to clients, its message is the whole client facing text.
+	// Anything that is not an Error is treated as internal and never leaves the service.
+	Error struct {
+		Kind    Kind
+		Message string
+		Fields  []Field
+	}
+
+	// Field points a validation error at one input field
+	Field struct {
+		Name    string
+		Message string
+	}
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func Validation(message string, fields ...Field) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(KindTooManyRequests, message)
+}
+
+// As returns the first domain error in the chain of err
+func As(err error) (*Error, bool) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	return nil, false
+}
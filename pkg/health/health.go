@@ -0,0 +1,50 @@
+package health
+
+import (
+	"clean-architecture/model/dto/json"
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const pingTimeout = 2 * time.Second
+
+type Checker struct {
+	db           *sql.DB
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(db *sql.DB) *Checker {
+	return &Checker{db: db}
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing while in-flight requests drain
+func (h *Checker) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness only reports that the process is able to serve http
+func (h *Checker) Liveness(c *gin.Context) {
+	json.NewResponseSuccess(c, nil, "alive", "99", "01")
+}
+
+// Readiness reports whether the service can take traffic, meaning it is not draining and the database answers
+func (h *Checker) Readiness(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		json.NewResponseServiceUnavailable(c, "shutting down", "99", "02")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), pingTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		json.NewResponseServiceUnavailable(c, "database unavailable", "99", "03")
+		return
+	}
+
+	json.NewResponseSuccess(c, nil, "ready", "99", "04")
+}
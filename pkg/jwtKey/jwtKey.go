@@ -0,0 +1,228 @@
+package jwtKey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrKeyExpired = errors.New("signing key expired")
+)
+
+type (
+	// Key is one entry of the key set; retired keys may only hold the public half
+	Key struct {
+		ID         string
+		Method     jwt.SigningMethod
+		PrivateKey crypto.Signer
+		PublicKey  crypto.PublicKey
+		ExpiresAt  *time.Time
+	}
+
+	KeySet struct {
+		signing *Key
+		keys    map[string]*Key
+		ordered []*Key
+	}
+
+	manifest struct {
+		Keys []manifestKey `json:"keys"`
+	}
+
+	manifestKey struct {
+		ID        string     `json:"kid"`
+		Path      string     `json:"path"`
+		Active    bool       `json:"active"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+	}
+
+	jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+)
+
+// LoadKeySet reads a JSON manifest listing PEM key files, paths are resolved relative to the manifest.
+// Exactly one key must be active, it signs new tokens while the others only verify until expires_at.
+func LoadKeySet(manifestPath string) (*KeySet, error) {
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("jwt key manifest: %w", err)
+	}
+
+	ks := &KeySet{keys: make(map[string]*Key)}
+	baseDir := filepath.Dir(manifestPath)
+	for _, entry := range m.Keys {
+		if entry.ID == "" {
+			return nil, errors.New("jwt key manifest: kid is required")
+		}
+		if _, exists := ks.keys[entry.ID]; exists {
+			return nil, fmt.Errorf("jwt key manifest: duplicate kid %s", entry.ID)
+		}
+
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+
+		key, err := loadKey(entry.ID, path)
+		if err != nil {
+			return nil, err
+		}
+		key.ExpiresAt = entry.ExpiresAt
+
+		if entry.Active {
+			if ks.signing != nil {
+				return nil, errors.New("jwt key manifest: more than one active key")
+			}
+			if key.PrivateKey == nil {
+				return nil, fmt.Errorf("jwt key manifest: active key %s has no private key", entry.ID)
+			}
+			if key.expired(time.Now()) {
+				return nil, fmt.Errorf("jwt key manifest: active key %s is expired", entry.ID)
+			}
+			ks.signing = key
+		}
+		ks.keys[entry.ID] = key
+		ks.ordered = append(ks.ordered, key)
+	}
+
+	if ks.signing == nil {
+		return nil, errors.New("jwt key manifest: no active key")
+	}
+
+	return ks, nil
+}
+
+func loadKey(kid, path string) (*Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %s: no PEM block found", kid)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported PEM type %s", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+	}
+
+	key := &Key{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.PublicKey = SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("jwt key %s: only RSA and Ed25519 keys are supported", kid)
+	}
+
+	return key, nil
+}
+
+func (k *Key) expired(now time.Time) bool {
+	return k.ExpiresAt != nil && now.After(*k.ExpiresAt)
+}
+
+// Sign signs the claims with the active key and stamps its kid in the header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+
+	return token.SignedString(ks.signing.PrivateKey)
+}
+
+// Parse verifies a token against the key named by its kid header
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if key.expired(time.Now()) {
+			return nil, ErrKeyExpired
+		}
+		// never let the token pick the algorithm
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, jwt.ErrInvalidKeyType
+		}
+
+		return key.PublicKey, nil
+	})
+}
+
+// JwksHandler publishes the public half of every key that still verifies
+func (ks *KeySet) JwksHandler(c *gin.Context) {
+	now := time.Now()
+	result := jwks{Keys: []jwk{}}
+	for _, key := range ks.ordered {
+		if key.expired(now) {
+			continue
+		}
+
+		entry := jwk{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			entry.Kty = "RSA"
+			entry.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			entry.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			entry.Kty = "OKP"
+			entry.Crv = "Ed25519"
+			entry.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		result.Keys = append(result.Keys, entry)
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, result)
+}
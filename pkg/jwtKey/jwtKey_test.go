@@ -0,0 +1,330 @@
+package jwtKey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// keyFiles writes an Ed25519 key "old" and an RSA key "new" to dir, each as a private and a public PEM file
+func keyFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, private := range map[string]interface{}{"old": edPrivate, "new": rsaPrivate} {
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writePEM(t, filepath.Join(dir, name+".pem"), "PRIVATE KEY", der)
+	}
+
+	edPublic, err := x509.MarshalPKIXPublicKey(edPrivate.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "old.pub.pem"), "PUBLIC KEY", edPublic)
+
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "new.pub.pem"), "PUBLIC KEY", rsaPublic)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadManifest writes the manifest entries next to the key files and loads it
+func loadManifest(t *testing.T, dir string, keys ...manifestKey) (*KeySet, error) {
+	t.Helper()
+
+	raw, err := json.Marshal(manifest{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return LoadKeySet(path)
+}
+
+func mustLoadManifest(t *testing.T, dir string, keys ...manifestKey) *KeySet {
+	t.Helper()
+
+	ks, err := loadManifest(t, dir, keys...)
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	return ks
+}
+
+func newClaims() *jwt.StandardClaims {
+	return &jwt.StandardClaims{Subject: "user", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+}
+
+// parseError unwraps the error returned by the key lookup from the validation error of jwt-go
+func parseError(err error) error {
+	var validationError *jwt.ValidationError
+	if errors.As(err, &validationError) && validationError.Inner != nil {
+		return validationError.Inner
+	}
+	return err
+}
+
+func TestLoadKeySetRejectsInvalidManifests(t *testing.T) {
+	dir := t.TempDir()
+	keyFiles(t, dir)
+	expired := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		keys []manifestKey
+	}{
+		{
+			name: "no active key",
+			keys: []manifestKey{{ID: "old", Path: "old.pem"}},
+		},
+		{
+			name: "two active keys",
+			keys: []manifestKey{{ID: "old", Path: "old.pem", Active: true}, {ID: "new", Path: "new.pem", Active: true}},
+		},
+		{
+			name: "duplicate kid",
+			keys: []manifestKey{{ID: "new", Path: "old.pem"}, {ID: "new", Path: "new.pem", Active: true}},
+		},
+		{
+			name: "missing kid",
+			keys: []manifestKey{{Path: "new.pem", Active: true}},
+		},
+		{
+			name: "active key without a private half",
+			keys: []manifestKey{{ID: "new", Path: "new.pub.pem", Active: true}},
+		},
+		{
+			name: "expired active key",
+			keys: []manifestKey{{ID: "new", Path: "new.pem", Active: true, ExpiresAt: &expired}},
+		},
+		{
+			name: "missing key file",
+			keys: []manifestKey{{ID: "new", Path: "gone.pem", Active: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadManifest(t, dir, tt.keys...); err == nil {
+				t.Error("LoadKeySet() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	keyFiles(t, dir)
+	retiresAt := time.Now().Add(time.Hour)
+	retiredAt := time.Now().Add(-time.Minute)
+
+	before := mustLoadManifest(t, dir, manifestKey{ID: "old", Path: "old.pem", Active: true})
+	oldToken, err := before.Sign(newClaims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	during := mustLoadManifest(t, dir,
+		manifestKey{ID: "new", Path: "new.pem", Active: true},
+		manifestKey{ID: "old", Path: "old.pub.pem", ExpiresAt: &retiresAt},
+	)
+	newToken, err := during.Sign(newClaims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	after := mustLoadManifest(t, dir,
+		manifestKey{ID: "new", Path: "new.pem", Active: true},
+		manifestKey{ID: "old", Path: "old.pub.pem", ExpiresAt: &retiredAt},
+	)
+
+	tests := []struct {
+		name    string
+		ks      *KeySet
+		token   string
+		wantKid string
+		wantErr error
+	}{
+		{
+			name:    "token of the active key",
+			ks:      before,
+			token:   oldToken,
+			wantKid: "old",
+		},
+		{
+			name:    "token of a retired key before it expires",
+			ks:      during,
+			token:   oldToken,
+			wantKid: "old",
+		},
+		{
+			name:    "token of the new active key",
+			ks:      during,
+			token:   newToken,
+			wantKid: "new",
+		},
+		{
+			name:    "token of a retired key after it expired",
+			ks:      after,
+			token:   oldToken,
+			wantErr: ErrKeyExpired,
+		},
+		{
+			name:    "token of a key the set does not know",
+			ks:      before,
+			token:   newToken,
+			wantErr: ErrUnknownKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.ks.Parse(tt.token, &jwt.StandardClaims{})
+			if tt.wantErr != nil {
+				if !errors.Is(parseError(err), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if kid := token.Header["kid"]; kid != tt.wantKid {
+				t.Errorf("Parse() kid = %v, want %s", kid, tt.wantKid)
+			}
+		})
+	}
+}
+
+func TestKeySetParseRejectsForeignAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	keyFiles(t, dir)
+	ks := mustLoadManifest(t, dir,
+		manifestKey{ID: "new", Path: "new.pem", Active: true},
+		manifestKey{ID: "old", Path: "old.pem"},
+	)
+
+	// an EdDSA signature presented under the kid of the RSA key
+	token := jwt.NewWithClaims(SigningMethodEdDSA, newClaims())
+	token.Header["kid"] = "new"
+	signed, err := token.SignedString(ks.keys["old"].PrivateKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if _, err := ks.Parse(signed, &jwt.StandardClaims{}); !errors.Is(parseError(err), jwt.ErrInvalidKeyType) {
+		t.Errorf("Parse() error = %v, want %v", err, jwt.ErrInvalidKeyType)
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, newClaims())
+	unsigned.Header["kid"] = "new"
+	none, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	if _, err := ks.Parse(none, &jwt.StandardClaims{}); err == nil {
+		t.Error("Parse() of an unsigned token error = nil, want an error")
+	}
+}
+
+func TestJwksHandlerPublishesKeysThatStillVerify(t *testing.T) {
+	dir := t.TempDir()
+	keyFiles(t, dir)
+	retiredAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		keys     []manifestKey
+		wantKids []string
+	}{
+		{
+			name: "active and retired key",
+			keys: []manifestKey{
+				{ID: "new", Path: "new.pem", Active: true},
+				{ID: "old", Path: "old.pub.pem"},
+			},
+			wantKids: []string{"new", "old"},
+		},
+		{
+			name: "expired retired key",
+			keys: []manifestKey{
+				{ID: "new", Path: "new.pem", Active: true},
+				{ID: "old", Path: "old.pub.pem", ExpiresAt: &retiredAt},
+			},
+			wantKids: []string{"new"},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := mustLoadManifest(t, dir, tt.keys...)
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ks.JwksHandler(ctx)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("JwksHandler() status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+			var got jwks
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("JwksHandler() body: %v", err)
+			}
+			if len(got.Keys) != len(tt.wantKids) {
+				t.Fatalf("JwksHandler() published %d keys, want %d", len(got.Keys), len(tt.wantKids))
+			}
+
+			for i, key := range got.Keys {
+				if key.Kid != tt.wantKids[i] {
+					t.Errorf("key %d kid = %s, want %s", i, key.Kid, tt.wantKids[i])
+				}
+				switch key.Kid {
+				case "new":
+					if key.Kty != "RSA" || key.Alg != "RS256" || key.N == "" || key.E == "" {
+						t.Errorf("RSA key published as %+v", key)
+					}
+				case "old":
+					if key.Kty != "OKP" || key.Crv != "Ed25519" || key.Alg != SigningMethodEdDSA.Alg() || key.X == "" {
+						t.Errorf("Ed25519 key published as %+v", key)
+					}
+				}
+			}
+		})
+	}
+}
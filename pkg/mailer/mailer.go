@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"clean-architecture/model/dto"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	Message struct {
+		To      string
+		Subject string
+		Body    string
+	}
+
+	// Mailer delivers transactional emails, implementations must be safe for concurrent use
+	Mailer interface {
+		Send(ctx context.Context, msg Message) error
+	}
+)
+
+// NewMailer builds the mailer selected by MAIL_DRIVER
+func NewMailer(cfg dto.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "", "outbox":
+		return NewOutboxMailer(cfg.From, cfg.OutboxDir), nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER: unknown driver %q", cfg.Driver)
+	}
+}
+
+// render builds an RFC 5322 plain text message
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"clean-architecture/utils"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// outboxMailer writes every message to a directory as .eml files, or to stdout when no directory is set,
+// so flows that send mail can be exercised without a mail server
+type outboxMailer struct {
+	from string
+	dir  string
+	mu   sync.Mutex
+	out  io.Writer
+}
+
+func NewOutboxMailer(from, dir string) Mailer {
+	return &outboxMailer{from: from, dir: dir, out: os.Stdout}
+}
+
+func (m *outboxMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	content := render(m.from, msg)
+	if m.dir == "" {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		_, err := fmt.Fprintf(m.out, "----- outbox mail -----\n%s\n-----------------------\n", content)
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	suffix, err := utils.GenerateRandomToken(6)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), suffix)
+
+	return os.WriteFile(filepath.Join(m.dir, name), content, 0o644)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"clean-architecture/model/dto/json"
+	"clean-architecture/pkg/domainError"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// domainErrorResponses maps each domain error kind to its status and error code
+var domainErrorResponses = map[domainError.Kind]struct {
+	status    int
+	errorCode string
+}{
+	domainError.KindValidation:      {http.StatusBadRequest, "01"},
+	domainError.KindUnauthorized:    {http.StatusUnauthorized, "02"},
+	domainError.KindForbidden:       {http.StatusForbidden, "03"},
+	domainError.KindNotFound:        {http.StatusNotFound, "04"},
+	domainError.KindConflict:        {http.StatusConflict, "05"},
+	domainError.KindTooManyRequests: {http.StatusTooManyRequests, "06"},
+}
+
+// internalErrorCode is used for every error that is not a domain error
+const internalErrorCode = "99"
+
+// ErrorHandler writes the response for errors handlers pass to json.AbortWithError.
+// Domain errors are answered with their own message, anything else is logged and answered generically.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		serviceCode := c.GetString(json.ServiceCodeKey)
+		if serviceCode == "" {
+			serviceCode = "00"
+		}
+
+		domainErr, ok := domainError.As(err)
+		if !ok {
+			json.NewResponseErrorFromCause(c, err, serviceCode, internalErrorCode)
+			return
+		}
+
+		response, ok := domainErrorResponses[domainErr.Kind]
+		if !ok {
+			json.NewResponseErrorFromCause(c, err, serviceCode, internalErrorCode)
+			return
+		}
+
+		if domainErr.Kind == domainError.KindValidation {
+			var fields []json.ValidationField
+			for _, field := range domainErr.Fields {
+				fields = append(fields, json.ValidationField{FieldName: field.Name, Message: field.Message})
+			}
+			json.NewResponseBadRequest(c, fields, domainErr.Message, serviceCode, response.errorCode)
+			return
+		}
+
+		json.NewResponseStatus(c, response.status, domainErr.Message, serviceCode, response.errorCode)
+	}
+}
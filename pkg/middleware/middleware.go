@@ -3,8 +3,10 @@ package middleware
 import (
 	"clean-architecture/model/dto/json"
 	"clean-architecture/model/entity"
+	"clean-architecture/pkg/jwtKey"
+	"context"
 	"encoding/base64"
-	"os"
+	"errors"
 	"strings"
 	"time"
 
@@ -13,72 +15,115 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// basic auth with encode
-func BasicAuthEncode(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		json.NewResponseUnauthorized(c, "Authorization header is required", "02", "01")
-		c.Abort()
-		return
-	}
+// ClientAuthenticator checks the credentials of a registered api client
+type ClientAuthenticator interface {
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.Client, bool, error)
+}
 
-	const prefix = "Basic "
-	if !strings.HasPrefix(authHeader, prefix) {
-		json.NewResponseUnauthorized(c, "Invalid authorization header", "02", "01")
-		c.Abort()
-		return
-	}
+// ClientAuth authenticates the calling client through http basic auth against the client registry.
+// When grantTypes are given the client must be registered for one of them.
+func ClientAuth(authenticator ClientAuthenticator, grantTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			json.NewResponseUnauthorized(c, "Authorization header is required", "02", "01")
+			c.Abort()
+			return
+		}
 
-	decoded, err := base64.StdEncoding.DecodeString(authHeader[len(prefix):])
-	if err != nil {
-		json.NewResponseUnauthorized(c, "Failed to decode authorization header", "02", "01")
-		c.Abort()
-		return
-	}
+		const prefix = "Basic "
+		if !strings.HasPrefix(authHeader, prefix) {
+			json.NewResponseUnauthorized(c, "Invalid authorization header", "02", "01")
+			c.Abort()
+			return
+		}
 
-	creds := strings.SplitN(string(decoded), ":", 2)
-	if len(creds) != 2 {
-		json.NewResponseUnauthorized(c, "Invalid authorization format", "02", "01")
-		c.Abort()
-		return
-	}
+		decoded, err := base64.StdEncoding.DecodeString(authHeader[len(prefix):])
+		if err != nil {
+			json.NewResponseUnauthorized(c, "Failed to decode authorization header", "02", "01")
+			c.Abort()
+			return
+		}
 
-	clientId := os.Getenv("CLIENT_ID")
-	clientSecret := os.Getenv("SECRET_CLIENT_ID")
+		creds := strings.SplitN(string(decoded), ":", 2)
+		if len(creds) != 2 {
+			json.NewResponseUnauthorized(c, "Invalid authorization format", "02", "01")
+			c.Abort()
+			return
+		}
 
-	if clientId != creds[0] || clientSecret != creds[1] {
-		json.NewResponseUnauthorized(c, "Invalid credentials", "02", "01")
-		c.Abort()
-		return
+		client, ok, err := authenticator.AuthenticateClient(c.Request.Context(), creds[0], creds[1])
+		if err != nil {
+			json.NewResponseErrorFromCause(c, err, "02", "06")
+			c.Abort()
+			return
+		}
+		if !ok {
+			json.NewResponseUnauthorized(c, "Invalid credentials", "02", "01")
+			c.Abort()
+			return
+		}
+		if !allowsAnyGrant(client, grantTypes) {
+			json.NewResponseForbidden(c, "client is not allowed to use this grant", "02", "08")
+			c.Abort()
+			return
+		}
+
+		c.Set("client", client)
+		c.Next()
 	}
+}
 
-	c.Next()
+func allowsAnyGrant(client *entity.Client, grantTypes []string) bool {
+	if len(grantTypes) == 0 {
+		return true
+	}
+	for _, grantType := range grantTypes {
+		if client.AllowsGrant(grantType) {
+			return true
+		}
+	}
+	return false
 }
 
 var (
-	applicationName  = "incubation-golang"
-	jwtSigningMethod = jwt.SigningMethodHS256
-	jwtSignatureKey  = []byte(os.Getenv("JWT_SECRET"))
+	ErrInvalidPurposeToken = errors.New("invalid token")
+
+	applicationName = "incubation-golang"
+	jwtKeySet       *jwtKey.KeySet
 )
 
+// InitJwtKeySet sets the keys used to sign and verify tokens, must be called before serving requests
+func InitJwtKeySet(keySet *jwtKey.KeySet) {
+	jwtKeySet = keySet
+}
+
+// TokenFamilyChecker reports whether the refresh token family an access token belongs to was revoked
+type TokenFamilyChecker interface {
+	IsTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+}
+
+// AgeVerificationChecker reports whether a user passed the age check required to buy age restricted goods
+type AgeVerificationChecker interface {
+	IsAgeVerified(ctx context.Context, userID string) (bool, error)
+}
+
 // generate token jwt
-func GenerateTokenJwt(id string, expiredAt int64) (string, error) {
-	loginExpDuration := time.Duration(expiredAt) * time.Minute
-	myExpiresAt := time.Now().Add(loginExpDuration).Unix()
+func GenerateTokenJwt(id string, role entity.Role, familyID string, mfaRequired bool, expiresIn time.Duration) (string, error) {
+	myExpiresAt := time.Now().Add(expiresIn).Unix()
 	claims := entity.JwtClaim{
 		StandardClaims: jwt.StandardClaims{
 			Issuer:    applicationName,
 			ExpiresAt: myExpiresAt,
 		},
-		ID: id,
+		ID:          id,
+		Roles:       string(role),
+		FamilyID:    familyID,
+		Purpose:     entity.TokenPurposeAccess,
+		MfaRequired: mfaRequired,
 	}
 
-	token := jwt.NewWithClaims(
-		jwtSigningMethod,
-		claims,
-	)
-
-	signedToken, err := token.SignedString(jwtSignatureKey)
+	signedToken, err := jwtKeySet.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -87,7 +132,54 @@ func GenerateTokenJwt(id string, expiredAt int64) (string, error) {
 
 }
 
-func JwtAuth() gin.HandlerFunc {
+// GenerateClientTokenJwt signs an access token for a client acting on its own behalf, it carries no user and no role
+func GenerateClientTokenJwt(clientID, scope string, expiresIn time.Duration) (string, error) {
+	claims := entity.JwtClaim{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    applicationName,
+			Subject:   clientID,
+			ExpiresAt: time.Now().Add(expiresIn).Unix(),
+		},
+		Purpose:  entity.TokenPurposeAccess,
+		ClientID: clientID,
+		Scope:    scope,
+	}
+
+	return jwtKeySet.Sign(claims)
+}
+
+// GeneratePurposeToken signs a short lived token usable only for the given purpose, subject binds it to extra state such as an email
+func GeneratePurposeToken(id, subject, purpose string, expiresIn time.Duration) (string, error) {
+	claims := entity.JwtClaim{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    applicationName,
+			Subject:   subject,
+			ExpiresAt: time.Now().Add(expiresIn).Unix(),
+		},
+		ID:      id,
+		Purpose: purpose,
+	}
+
+	return jwtKeySet.Sign(claims)
+}
+
+// ParsePurposeToken verifies a token produced by GeneratePurposeToken for the same purpose
+func ParsePurposeToken(tokenString, purpose string) (*entity.JwtClaim, error) {
+	claims := &entity.JwtClaim{}
+	token, err := jwtKeySet.Parse(tokenString, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.Purpose != purpose {
+		return nil, ErrInvalidPurposeToken
+	}
+
+	return claims, nil
+}
+
+// JwtAuth admits access tokens issued to a user. Client credentials tokens carry no user and no role,
+// every route behind JwtAuth acts for a user so they are turned away here.
+func JwtAuth(familyChecker TokenFamilyChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if !strings.Contains(authHeader, "Bearer") {
@@ -98,16 +190,121 @@ func JwtAuth() gin.HandlerFunc {
 
 		tokenString := strings.TrimSpace(strings.Replace(authHeader, "Bearer", "", -1))
 		claims := &entity.JwtClaim{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtSignatureKey, nil
-		})
-		if err != nil || !token.Valid {
+		token, err := jwtKeySet.Parse(tokenString, claims)
+		// purpose tokens such as email verification links must never authenticate api calls
+		if err != nil || !token.Valid || claims.Purpose != entity.TokenPurposeAccess || claims.ID == "" {
 			json.NewResponseUnauthorized(c, "Invalid Token", "02", "01")
 			c.Abort()
 			return
 		}
 
+		if claims.FamilyID != "" {
+			revoked, err := familyChecker.IsTokenFamilyRevoked(c.Request.Context(), claims.FamilyID)
+			if err != nil || revoked {
+				json.NewResponseUnauthorized(c, "Invalid Token", "02", "01")
+				c.Abort()
+				return
+			}
+		}
+
 		c.Set("userID", claims.ID)
+		c.Set("familyID", claims.FamilyID)
+		c.Set("role", claims.Roles)
+		c.Set("mfaRequired", claims.MfaRequired)
+
+		c.Next()
+	}
+}
+
+// OptionalJwtAuth lets anonymous requests through, a request that sends an Authorization header must pass JwtAuth
+func OptionalJwtAuth(familyChecker TokenFamilyChecker) gin.HandlerFunc {
+	jwtAuth := JwtAuth(familyChecker)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		jwtAuth(c)
+	}
+}
+
+// RequestTimeout bounds every downstream query of a request with the given deadline
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequireRole only lets through requests whose token carries one of the given roles, must run after JwtAuth
+func RequireRole(roles ...entity.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("mfaRequired") {
+			json.NewResponseForbidden(c, "two-factor authentication required", "02", "05")
+			c.Abort()
+			return
+		}
+
+		role := entity.Role(c.GetString("role"))
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		json.NewResponseForbidden(c, "insufficient role", "02", "02")
+		c.Abort()
+	}
+}
+
+// GetActor returns the caller JwtAuth authenticated, must run after JwtAuth
+func GetActor(c *gin.Context) entity.Actor {
+	return entity.Actor{
+		ID:          c.GetString("userID"),
+		Role:        entity.Role(c.GetString("role")),
+		MfaRequired: c.GetBool("mfaRequired"),
+	}
+}
+
+// RequirePermission only lets through requests whose role grants the permission, must run after JwtAuth
+func RequirePermission(permission entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("mfaRequired") {
+			json.NewResponseForbidden(c, "two-factor authentication required", "02", "05")
+			c.Abort()
+			return
+		}
+
+		role := entity.Role(c.GetString("role"))
+		if !role.HasPermission(permission) {
+			json.NewResponseForbidden(c, "insufficient permission", "02", "02")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAgeVerified blocks purchase related endpoints for accounts whose age is not verified yet, must run after JwtAuth
+func RequireAgeVerified(ageChecker AgeVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verified, err := ageChecker.IsAgeVerified(c.Request.Context(), c.GetString("userID"))
+		if err != nil {
+			// a token that outlived its account answers 404 through the error middleware
+			json.AbortWithError(c, err, "02")
+			return
+		}
+		if !verified {
+			json.NewResponseForbidden(c, "age verification required", "02", "04")
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
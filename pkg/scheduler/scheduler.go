@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job is one run of a periodic task, it should stop early when ctx is done
+type Job func(ctx context.Context) error
+
+type task struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs registered jobs on fixed intervals in the background of the service
+type Scheduler struct {
+	tasks  []task
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job, a non-positive interval disables it
+func (s *Scheduler) Add(name string, interval time.Duration, job Job) {
+	if interval <= 0 {
+		log.Info().Msg("scheduler: " + name + " disabled")
+		return
+	}
+	s.tasks = append(s.tasks, task{name: name, interval: interval, job: job})
+}
+
+// Start runs every job once per interval until Stop is called, a failing run is logged and retried on the next tick
+func (s *Scheduler) Start(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	s.cancel = cancel
+
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go func(t task) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := t.job(ctx); err != nil && ctx.Err() == nil {
+						log.Error().Msg("scheduler." + t.name + ".err : " + err.Error())
+					}
+				}
+			}
+		}(t)
+	}
+}
+
+// Stop cancels the running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
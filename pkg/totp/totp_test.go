@@ -0,0 +1,149 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// the RFC lists eight digit codes, six digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code() error = nil, want a decoding error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		skew     int64
+		wantStep int64
+		wantOk   bool
+	}{
+		{
+			name:     "current step",
+			secret:   rfcSecret,
+			code:     codeAt(current),
+			skew:     1,
+			wantStep: current,
+			wantOk:   true,
+		},
+		{
+			name:     "previous step within the skew",
+			secret:   rfcSecret,
+			code:     codeAt(current - 1),
+			skew:     1,
+			wantStep: current - 1,
+			wantOk:   true,
+		},
+		{
+			name:     "next step within the skew",
+			secret:   rfcSecret,
+			code:     codeAt(current + 1),
+			skew:     1,
+			wantStep: current + 1,
+			wantOk:   true,
+		},
+		{
+			name:   "step outside the skew",
+			secret: rfcSecret,
+			code:   codeAt(current - 2),
+			skew:   1,
+		},
+		{
+			name:   "previous step without skew",
+			secret: rfcSecret,
+			code:   codeAt(current - 1),
+			skew:   0,
+		},
+		{
+			name:     "lower case secret",
+			secret:   strings.ToLower(rfcSecret),
+			code:     codeAt(current),
+			skew:     0,
+			wantStep: current,
+			wantOk:   true,
+		},
+		{
+			name:   "code too short",
+			secret: rfcSecret,
+			code:   codeAt(current)[1:],
+			skew:   1,
+		},
+		{
+			name:   "code too long",
+			secret: rfcSecret,
+			code:   codeAt(current) + "0",
+			skew:   1,
+		},
+		{
+			name:   "invalid secret",
+			secret: "not base32!",
+			code:   "123456",
+			skew:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(tt.secret, tt.code, now, tt.skew)
+			if ok != tt.wantOk || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("len(GenerateSecret()) = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 0); err != nil {
+		t.Errorf("Code() of a generated secret error = %v", err)
+	}
+}
@@ -2,14 +2,88 @@ package validation
 
 import (
 	"clean-architecture/model/dto/json"
+	stdjson "encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
 	"github.com/stoewer/go-strcase"
 )
 
+// RegisterValidations adds the custom tags used by the dto binding rules to gin's validator
+func RegisterValidations() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+
+	err := v.RegisterValidation("DateOnly", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(time.DateOnly, fl.Field().String())
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = v.RegisterValidation("Slug", func(fl validator.FieldLevel) bool {
+		return slugPattern.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+
+	return v.RegisterValidation("TimeOfDay", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("15:04", fl.Field().String())
+		return err == nil
+	})
+}
+
+// slugPattern is lowercase words of letters and digits joined by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// BindJSON binds the request body into obj, a pointer to a payload pointer, and describes why the body cannot be
+// used: missing or null, malformed, holding a value of the wrong type or breaking a binding rule. An empty result
+// means the payload is bound and valid
+func BindJSON(ctx *gin.Context, obj interface{}) []json.ValidationField {
+	// a json null decoded into a payload pointer leaves it nil and panics gin's validator, so the body is bound into
+	// an allocated payload instead, where null leaves the zero value for the binding rules to report
+	target := obj
+	if payload := reflect.ValueOf(obj).Elem(); payload.Kind() == reflect.Ptr {
+		if payload.IsNil() {
+			payload.Set(reflect.New(payload.Type().Elem()))
+		}
+		target = payload.Interface()
+	}
+
+	err := ctx.ShouldBindJSON(target)
+	if err == nil {
+		return nil
+	}
+
+	if validationFields := GetValidationError(err); len(validationFields) > 0 {
+		return validationFields
+	}
+
+	log.Debug().Msg(fmt.Sprintf("bindError : %v", err))
+	var typeError *stdjson.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return []json.ValidationField{{FieldName: "body", Message: "required"}}
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return []json.ValidationField{{FieldName: typeError.Field, Message: "must be of type " + typeError.Type.String()}}
+	default:
+		return []json.ValidationField{{FieldName: "body", Message: "invalid format json"}}
+	}
+}
+
 func GetValidationError(err error) []json.ValidationField {
 	var validationFields []json.ValidationField
 	if ve, ok := err.(validator.ValidationErrors); ok {
@@ -63,6 +137,16 @@ func formatMessage(err validator.FieldError) string {
 		message = "minimum value is not exceed"
 	case "max":
 		message = "max value is exceed"
+	case "len":
+		message = "length must be " + err.Param()
+	case "oneof":
+		message = "must be one of " + err.Param()
+	case "uuid":
+		message = "must be a uuid"
+	case "Slug":
+		message = "must be lowercase letters, digits and single hyphens"
+	case "TimeOfDay":
+		message = "invalid format time, expected HH:MM"
 	}
 
 	return message
@@ -1,16 +1,90 @@
 package router
 
 import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/mailer"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/scheduler"
+	"clean-architecture/src/cart/cartDelivery"
+	"clean-architecture/src/cart/cartRepository"
+	"clean-architecture/src/cart/cartUseCase"
+	"clean-architecture/src/client/clientDelivery"
+	"clean-architecture/src/client/clientRepository"
+	"clean-architecture/src/client/clientUseCase"
+	"clean-architecture/src/inventory/inventoryDelivery"
+	"clean-architecture/src/inventory/inventoryRepository"
+	"clean-architecture/src/inventory/inventoryUseCase"
+	"clean-architecture/src/location/locationDelivery"
+	"clean-architecture/src/location/locationRepository"
+	"clean-architecture/src/location/locationUseCase"
+	"clean-architecture/src/order/orderDelivery"
+	"clean-architecture/src/order/orderRepository"
+	"clean-architecture/src/order/orderUseCase"
+	"clean-architecture/src/product/productDelivery"
+	"clean-architecture/src/product/productRepository"
+	"clean-architecture/src/product/productUseCase"
 	"clean-architecture/src/user/userDelivery"
 	"clean-architecture/src/user/userRepository"
 	"clean-architecture/src/user/userUseCase"
+	"clean-architecture/src/variant/variantDelivery"
+	"clean-architecture/src/variant/variantRepository"
+	"clean-architecture/src/variant/variantUseCase"
+	"context"
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
 )
 
-func InitRoute(v1Group *gin.RouterGroup, db *sql.DB) {
+func InitRoute(v1Group *gin.RouterGroup, db *sql.DB, configData dto.ConfigData, mail mailer.Mailer, jobs *scheduler.Scheduler) {
 	userRepo := userRepository.NewUserRepository(db)
-	userUc := userUseCase.NewUserUseCase(userRepo)
-	userDelivery.NewUserDelivery(v1Group, userUc)
+	loginAttemptRepo := userRepository.NewLoginAttemptRepository(db)
+	if configData.LoginProtectionConfig.Store == "memory" {
+		loginAttemptRepo = userRepository.NewLoginAttemptMemoryRepository()
+	}
+	userUc := userUseCase.NewUserUseCase(userRepo, loginAttemptRepo, mail, configData)
+	jobs.Add("purge_deleted_users", configData.RetentionConfig.UserPurgeInterval, func(ctx context.Context) error {
+		_, err := userUc.PurgeExpiredUsers(ctx)
+		return err
+	})
+
+	clientRepo := clientRepository.NewClientRepository(db)
+	clientUc := clientUseCase.NewClientUseCase(clientRepo, configData)
+	clientDelivery.NewClientDelivery(v1Group, clientUc, userUc)
+
+	productRepo := productRepository.NewProductRepository(db)
+	productUc := productUseCase.NewProductUseCase(productRepo)
+	jwtAuth := middleware.JwtAuth(userUc)
+	productDelivery.NewProductDelivery(v1Group, productUc, jwtAuth)
+
+	variantRepo := variantRepository.NewVariantRepository(db)
+	variantUc := variantUseCase.NewVariantUseCase(variantRepo, productRepo)
+	variantDelivery.NewVariantDelivery(v1Group, variantUc, jwtAuth)
+
+	locationRepo := locationRepository.NewLocationRepository(db)
+	locationUc := locationUseCase.NewLocationUseCase(locationRepo)
+	locationDelivery.NewLocationDelivery(v1Group, locationUc, jwtAuth)
+
+	inventoryRepo := inventoryRepository.NewInventoryRepository(db)
+	inventoryUc := inventoryUseCase.NewInventoryUseCase(inventoryRepo, variantRepo, locationRepo, configData)
+	jobs.Add("expire_stock_reservations", configData.InventoryConfig.ReservationExpiryInterval, func(ctx context.Context) error {
+		_, err := inventoryUc.ExpireReservations(ctx)
+		return err
+	})
+	inventoryDelivery.NewInventoryDelivery(v1Group, inventoryUc, jwtAuth)
+
+	cartRepo := cartRepository.NewCartRepository(db)
+	cartUc := cartUseCase.NewCartUseCase(cartRepo, variantRepo, productRepo, inventoryRepo, locationRepo, configData)
+	jobs.Add("expire_abandoned_carts", configData.CartConfig.ExpiryInterval, func(ctx context.Context) error {
+		_, err := cartUc.ExpireAbandonedCarts(ctx)
+		return err
+	})
+	cartDelivery.NewCartDelivery(v1Group, cartUc, middleware.OptionalJwtAuth(userUc))
+
+	orderRepo := orderRepository.NewOrderRepository(db, inventoryRepo)
+	orderUc := orderUseCase.NewOrderUseCase(orderRepo, cartRepo, variantRepo, productRepo, locationRepo, inventoryUc, configData)
+	orderDelivery.NewOrderDelivery(v1Group, orderUc, jwtAuth, middleware.RequireAgeVerified(userUc))
+
+	// login merges the guest cart, so the user routes are wired once carts exist
+	userDelivery.NewUserDelivery(v1Group, userUc, middleware.ClientAuth(clientUc, entity.GrantTypePassword), cartUc)
 }
@@ -0,0 +1,113 @@
+package cartDelivery
+
+import (
+	"clean-architecture/model/dto/cartDto"
+	"clean-architecture/model/dto/json"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/cart"
+
+	"github.com/gin-gonic/gin"
+)
+
+type cartDelivery struct {
+	cartUC cart.CartUseCase
+}
+
+// NewCartDelivery serves guests through the cart token header and users through their access token,
+// optionalJwtAuth must let requests without an Authorization header through
+func NewCartDelivery(v1Group *gin.RouterGroup, cartUC cart.CartUseCase, optionalJwtAuth gin.HandlerFunc) {
+	handler := cartDelivery{
+		cartUC: cartUC,
+	}
+
+	cartGroup := v1Group.Group("", optionalJwtAuth)
+	{
+		cartGroup.POST("/carts", handler.createCart)
+		cartGroup.GET("/cart", handler.getCart)
+		cartGroup.DELETE("/cart", handler.clearCart)
+		cartGroup.POST("/cart/items", handler.addItem)
+		cartGroup.PUT("/cart/items/:variant_id", handler.updateItem)
+		cartGroup.DELETE("/cart/items/:variant_id", handler.removeItem)
+	}
+}
+
+func cartOwner(ctx *gin.Context) cartDto.CartOwner {
+	return cartDto.CartOwner{
+		UserID:    middleware.GetActor(ctx).ID,
+		CartToken: ctx.GetHeader(cartDto.CartTokenHeader),
+	}
+}
+
+func (c *cartDelivery) createCart(ctx *gin.Context) {
+	view, err := c.cartUC.CreateCart(ctx.Request.Context(), cartOwner(ctx))
+	if err != nil {
+		json.AbortWithError(ctx, err, "82")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "82", "02")
+}
+
+func (c *cartDelivery) getCart(ctx *gin.Context) {
+	view, err := c.cartUC.GetCart(ctx.Request.Context(), cartOwner(ctx))
+	if err != nil {
+		json.AbortWithError(ctx, err, "83")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "83", "01")
+}
+
+func (c *cartDelivery) addItem(ctx *gin.Context) {
+	var itemPayload *cartDto.AddItemRequest
+	if validationError := validation.BindJSON(ctx, &itemPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "84", "01")
+		return
+	}
+
+	view, err := c.cartUC.AddItem(ctx.Request.Context(), cartOwner(ctx), itemPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "84")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "84", "02")
+}
+
+func (c *cartDelivery) updateItem(ctx *gin.Context) {
+	variantID := ctx.Param("variant_id")
+	var itemPayload *cartDto.UpdateItemRequest
+	if validationError := validation.BindJSON(ctx, &itemPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "85", "01")
+		return
+	}
+
+	view, err := c.cartUC.UpdateItem(ctx.Request.Context(), cartOwner(ctx), variantID, itemPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "85")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "85", "02")
+}
+
+func (c *cartDelivery) removeItem(ctx *gin.Context) {
+	view, err := c.cartUC.RemoveItem(ctx.Request.Context(), cartOwner(ctx), ctx.Param("variant_id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "86")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "86", "01")
+}
+
+func (c *cartDelivery) clearCart(ctx *gin.Context) {
+	view, err := c.cartUC.ClearCart(ctx.Request.Context(), cartOwner(ctx))
+	if err != nil {
+		json.AbortWithError(ctx, err, "87")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, view, "success", "87", "01")
+}
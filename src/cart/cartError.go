@@ -0,0 +1,20 @@
+package cart
+
+import (
+	"clean-architecture/model/dto/cartDto"
+	"clean-architecture/pkg/domainError"
+	"fmt"
+)
+
+var (
+	ErrCartTokenRequired = domainError.Unauthorized("a cart token or login is required")
+	ErrInvalidCartToken  = domainError.Unauthorized("invalid cart token")
+
+	ErrCartNotFound = domainError.NotFound("cart not found")
+	ErrItemNotFound = domainError.NotFound("item not in cart")
+
+	ErrVariantUnavailable = domainError.Conflict("variant is not available for sale")
+
+	ErrQuantityTooLarge = domainError.Validation("bad request", domainError.Field{Name: "quantity", Message: fmt.Sprintf("at most %d of a variant per cart", cartDto.MaxItemQuantity)})
+	ErrCartFull         = domainError.Validation("bad request", domainError.Field{Name: "variant_id", Message: fmt.Sprintf("a cart holds at most %d different variants", cartDto.MaxCartLines)})
+)
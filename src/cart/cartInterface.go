@@ -0,0 +1,33 @@
+package cart
+
+import (
+	"clean-architecture/model/dto/cartDto"
+	"clean-architecture/model/entity"
+	"context"
+	"time"
+)
+
+type CartRepository interface {
+	CreateGuestCart(ctx context.Context) (string, error)
+	EnsureUserCart(ctx context.Context, userID string) (string, error)
+	GetCartByID(ctx context.Context, id string) (*entity.Cart, error)
+	GetCartItems(ctx context.Context, cartID string) ([]*entity.CartItem, error)
+	AddItem(ctx context.Context, cartID, variantID string, quantity int, unitPrice int64, maxQuantity int) error
+	SetItemQuantity(ctx context.Context, cartID, variantID string, quantity int) error
+	RemoveItem(ctx context.Context, cartID, variantID string) error
+	ClearCart(ctx context.Context, cartID string) error
+	RepriceItems(ctx context.Context, cartID string) error
+	MergeCarts(ctx context.Context, guestCartID, userCartID string, maxQuantity, maxLines int) error
+	DeleteAbandonedCarts(ctx context.Context, guestIdleSince, userIdleSince time.Time) (int, error)
+}
+
+type CartUseCase interface {
+	CreateCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error)
+	GetCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error)
+	AddItem(ctx context.Context, owner cartDto.CartOwner, request *cartDto.AddItemRequest) (*cartDto.CartResponse, error)
+	UpdateItem(ctx context.Context, owner cartDto.CartOwner, variantID string, request *cartDto.UpdateItemRequest) (*cartDto.CartResponse, error)
+	RemoveItem(ctx context.Context, owner cartDto.CartOwner, variantID string) (*cartDto.CartResponse, error)
+	ClearCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error)
+	MergeGuestCart(ctx context.Context, userID, cartToken string) error
+	ExpireAbandonedCarts(ctx context.Context) (int, error)
+}
@@ -0,0 +1,253 @@
+package cartRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/cart"
+	"clean-architecture/src/variant"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type cartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) cart.CartRepository {
+	return &cartRepository{db}
+}
+
+func (repo *cartRepository) CreateGuestCart(ctx context.Context) (string, error) {
+	var id string
+	err := repo.db.QueryRowContext(ctx, `INSERT INTO carts DEFAULT VALUES RETURNING id`).Scan(&id)
+	return id, err
+}
+
+// EnsureUserCart returns the cart of a user, creating it on first use
+func (repo *cartRepository) EnsureUserCart(ctx context.Context, userID string) (string, error) {
+	var id string
+	err := repo.db.QueryRowContext(ctx, `INSERT INTO carts (user_id) VALUES ($1)
+		ON CONFLICT (user_id) WHERE user_id IS NOT NULL DO UPDATE SET updated_at = carts.updated_at
+		RETURNING id`, userID).Scan(&id)
+	return id, err
+}
+
+func (repo *cartRepository) GetCartByID(ctx context.Context, id string) (*entity.Cart, error) {
+	c := new(entity.Cart)
+	err := repo.db.QueryRowContext(ctx, `SELECT id, user_id, created_at, updated_at FROM carts WHERE id = $1`, id).
+		Scan(&c.ID, &c.UserID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, cart.ErrCartNotFound)
+	}
+
+	return c, nil
+}
+
+// GetCartItems returns the lines of a cart in the order they were added, joined with the current catalog data
+func (repo *cartRepository) GetCartItems(ctx context.Context, cartID string) ([]*entity.CartItem, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT ci.variant_id, p.id, p.name, p.slug, v.sku, v.options, ci.quantity, ci.unit_price, v.price,
+			v.active AND p.status = 'active', ci.created_at
+		FROM cart_items ci
+		JOIN product_variants v ON v.id = ci.variant_id
+		JOIN products p ON p.id = v.product_id
+		WHERE ci.cart_id = $1
+		ORDER BY ci.created_at, v.sku`, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*entity.CartItem{}
+	for rows.Next() {
+		item := new(entity.CartItem)
+		var encodedOptions []byte
+		err := rows.Scan(
+			&item.VariantID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.ProductSlug,
+			&item.SKU,
+			&encodedOptions,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.CurrentPrice,
+			&item.Purchasable,
+			&item.AddedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encodedOptions, &item.Options); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// AddItem adds to the quantity already in the cart, refusing when the total would exceed maxQuantity
+func (repo *cartRepository) AddItem(ctx context.Context, cartID, variantID string, quantity int, unitPrice int64, maxQuantity int) error {
+	result, err := repo.db.ExecContext(ctx, `INSERT INTO cart_items (cart_id, variant_id, quantity, unit_price) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cart_id, variant_id) DO UPDATE
+			SET quantity = cart_items.quantity + EXCLUDED.quantity, unit_price = EXCLUDED.unit_price, updated_at = NOW()
+			WHERE cart_items.quantity + EXCLUDED.quantity <= $5`,
+		cartID, variantID, quantity, unitPrice, maxQuantity)
+	if err != nil {
+		if isPqError(err, pqForeignKeyViolation) {
+			return variant.ErrVariantNotFound
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return cart.ErrQuantityTooLarge
+	}
+
+	return nil
+}
+
+func (repo *cartRepository) SetItemQuantity(ctx context.Context, cartID, variantID string, quantity int) error {
+	return execAffectingOne(ctx, repo.db, cart.ErrItemNotFound,
+		`UPDATE cart_items SET quantity = $3, updated_at = NOW() WHERE cart_id = $1 AND variant_id = $2`, cartID, variantID, quantity)
+}
+
+func (repo *cartRepository) RemoveItem(ctx context.Context, cartID, variantID string) error {
+	return execAffectingOne(ctx, repo.db, cart.ErrItemNotFound,
+		`DELETE FROM cart_items WHERE cart_id = $1 AND variant_id = $2`, cartID, variantID)
+}
+
+func (repo *cartRepository) ClearCart(ctx context.Context, cartID string) error {
+	_, err := repo.db.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id = $1`, cartID)
+	return err
+}
+
+// RepriceItems stores the current variant prices as the prices the customer has now seen and marks the cart as active
+func (repo *cartRepository) RepriceItems(ctx context.Context, cartID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE cart_items ci SET unit_price = v.price, updated_at = NOW()
+		FROM product_variants v
+		WHERE ci.cart_id = $1 AND v.id = ci.variant_id AND ci.unit_price <> v.price`, cartID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE carts SET updated_at = NOW() WHERE id = $1`, cartID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MergeCarts moves the lines of a guest cart into a user cart and deletes the guest cart.
+// Quantities of a variant in both carts are added up to maxQuantity. Variants only in the guest cart are moved
+// newest first while the user cart has fewer than maxLines lines, the rest are dropped with the guest cart.
+func (repo *cartRepository) MergeCarts(ctx context.Context, guestCartID, userCartID string, maxQuantity, maxLines int) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// locking the guest cart makes a second merge of the same cart find nothing
+	var id string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM carts WHERE id = $1 AND user_id IS NULL FOR UPDATE`, guestCartID).Scan(&id)
+	if err != nil {
+		return notFound(err, cart.ErrCartNotFound)
+	}
+
+	// locking the user cart keeps a concurrent merge from counting the same free lines
+	var lines int
+	err = tx.QueryRowContext(ctx, `SELECT (SELECT COUNT(*) FROM cart_items WHERE cart_id = carts.id) FROM carts WHERE id = $1 FOR UPDATE`,
+		userCartID).Scan(&lines)
+	if err != nil {
+		return notFound(err, cart.ErrCartNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO cart_items (cart_id, variant_id, quantity, unit_price, created_at)
+		SELECT $2, guest.variant_id, LEAST(guest.quantity, $3), guest.unit_price, guest.created_at FROM cart_items guest
+		WHERE guest.cart_id = $1 AND (
+			EXISTS (SELECT 1 FROM cart_items own WHERE own.cart_id = $2 AND own.variant_id = guest.variant_id)
+			OR guest.variant_id IN (
+				SELECT fresh.variant_id FROM cart_items fresh
+				WHERE fresh.cart_id = $1
+					AND NOT EXISTS (SELECT 1 FROM cart_items own WHERE own.cart_id = $2 AND own.variant_id = fresh.variant_id)
+				ORDER BY fresh.created_at DESC LIMIT $4))
+		ON CONFLICT (cart_id, variant_id) DO UPDATE
+			SET quantity = LEAST(cart_items.quantity + EXCLUDED.quantity, $3), updated_at = NOW()`,
+		guestCartID, userCartID, maxQuantity, max(maxLines-lines, 0))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE id = $1`, guestCartID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE carts SET updated_at = NOW() WHERE id = $1`, userCartID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAbandonedCarts removes guest and user carts untouched since the given times, their items go with them
+func (repo *cartRepository) DeleteAbandonedCarts(ctx context.Context, guestIdleSince, userIdleSince time.Time) (int, error) {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM carts
+		WHERE (user_id IS NULL AND updated_at < $1) OR (user_id IS NOT NULL AND updated_at < $2)`, guestIdleSince, userIdleSince)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// execAffectingOne reports notFoundErr when the statement matched nothing
+func execAffectingOne(ctx context.Context, db *sql.DB, notFoundErr error, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return notFound(err, notFoundErr)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFoundErr
+	}
+
+	return nil
+}
+
+const (
+	pqForeignKeyViolation       = "23503"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
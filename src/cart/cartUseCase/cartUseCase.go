@@ -0,0 +1,268 @@
+package cartUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/cartDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/src/cart"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/product"
+	"clean-architecture/src/variant"
+	"context"
+	"errors"
+	"time"
+)
+
+type CartUC struct {
+	cartRepo      cart.CartRepository
+	variantRepo   variant.VariantRepository
+	productRepo   product.ProductRepository
+	inventoryRepo inventory.InventoryRepository
+	locationRepo  location.LocationRepository
+	config        dto.ConfigData
+}
+
+func NewCartUseCase(cartRepo cart.CartRepository, variantRepo variant.VariantRepository, productRepo product.ProductRepository,
+	inventoryRepo inventory.InventoryRepository, locationRepo location.LocationRepository, config dto.ConfigData) cart.CartUseCase {
+	return &CartUC{cartRepo, variantRepo, productRepo, inventoryRepo, locationRepo, config}
+}
+
+// CreateCart starts a guest cart, a logged-in user simply gets their own cart back
+func (useCase *CartUC) CreateCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error) {
+	if owner.UserID != "" {
+		return useCase.GetCart(ctx, owner)
+	}
+
+	id, err := useCase.cartRepo.CreateGuestCart(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, id, true)
+}
+
+func (useCase *CartUC) GetCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error) {
+	cartID, err := useCase.resolveCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, cartID, owner.UserID == "")
+}
+
+// AddItem puts a variant in the cart or adds to the quantity already there, only purchasable variants can be added
+func (useCase *CartUC) AddItem(ctx context.Context, owner cartDto.CartOwner, request *cartDto.AddItemRequest) (*cartDto.CartResponse, error) {
+	cartID, err := useCase.resolveCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := useCase.variantRepo.GetVariantByID(ctx, request.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := useCase.productRepo.GetProductByID(ctx, v.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Active || p.Status != entity.ProductStatusActive {
+		return nil, cart.ErrVariantUnavailable
+	}
+
+	items, err := useCase.cartRepo.GetCartItems(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) >= cartDto.MaxCartLines && !containsVariant(items, v.ID) {
+		return nil, cart.ErrCartFull
+	}
+
+	if err := useCase.cartRepo.AddItem(ctx, cartID, v.ID, request.Quantity, v.Price, cartDto.MaxItemQuantity); err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, cartID, owner.UserID == "")
+}
+
+func containsVariant(items []*entity.CartItem, variantID string) bool {
+	for _, item := range items {
+		if item.VariantID == variantID {
+			return true
+		}
+	}
+	return false
+}
+
+func (useCase *CartUC) UpdateItem(ctx context.Context, owner cartDto.CartOwner, variantID string, request *cartDto.UpdateItemRequest) (*cartDto.CartResponse, error) {
+	cartID, err := useCase.resolveCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := useCase.cartRepo.SetItemQuantity(ctx, cartID, variantID, request.Quantity); err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, cartID, owner.UserID == "")
+}
+
+func (useCase *CartUC) RemoveItem(ctx context.Context, owner cartDto.CartOwner, variantID string) (*cartDto.CartResponse, error) {
+	cartID, err := useCase.resolveCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := useCase.cartRepo.RemoveItem(ctx, cartID, variantID); err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, cartID, owner.UserID == "")
+}
+
+func (useCase *CartUC) ClearCart(ctx context.Context, owner cartDto.CartOwner) (*cartDto.CartResponse, error) {
+	cartID, err := useCase.resolveCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := useCase.cartRepo.ClearCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	return useCase.cartView(ctx, cartID, owner.UserID == "")
+}
+
+// MergeGuestCart moves the cart a visitor filled before logging in into the cart of the user
+func (useCase *CartUC) MergeGuestCart(ctx context.Context, userID, cartToken string) error {
+	guestCartID, err := useCase.guestCartID(ctx, cartToken)
+	if err != nil {
+		return err
+	}
+
+	userCartID, err := useCase.cartRepo.EnsureUserCart(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return useCase.cartRepo.MergeCarts(ctx, guestCartID, userCartID, cartDto.MaxItemQuantity, cartDto.MaxCartLines)
+}
+
+// ExpireAbandonedCarts removes carts nobody touched within their life time
+func (useCase *CartUC) ExpireAbandonedCarts(ctx context.Context) (int, error) {
+	now := time.Now()
+	return useCase.cartRepo.DeleteAbandonedCarts(ctx,
+		now.Add(-useCase.config.CartConfig.GuestCartLifeTime),
+		now.Add(-useCase.config.CartConfig.UserCartLifeTime))
+}
+
+// resolveCart finds the cart of the caller, a user without a cart gets an empty one
+func (useCase *CartUC) resolveCart(ctx context.Context, owner cartDto.CartOwner) (string, error) {
+	if owner.UserID != "" {
+		return useCase.cartRepo.EnsureUserCart(ctx, owner.UserID)
+	}
+	if owner.CartToken == "" {
+		return "", cart.ErrCartTokenRequired
+	}
+
+	return useCase.guestCartID(ctx, owner.CartToken)
+}
+
+// guestCartID verifies a cart token, the cart must still exist and not have been merged into a user cart
+func (useCase *CartUC) guestCartID(ctx context.Context, cartToken string) (string, error) {
+	claims, err := middleware.ParsePurposeToken(cartToken, entity.TokenPurposeCart)
+	if err != nil {
+		return "", cart.ErrInvalidCartToken
+	}
+
+	c, err := useCase.cartRepo.GetCartByID(ctx, claims.ID)
+	if err != nil {
+		return "", err
+	}
+	if c.UserID != nil {
+		return "", cart.ErrCartNotFound
+	}
+
+	return c.ID, nil
+}
+
+// cartView prices the cart as it is read: every line gets the current variant price, lines that can no
+// longer be bought or exceed the online stock are flagged, and the new prices count as seen from now on
+func (useCase *CartUC) cartView(ctx context.Context, cartID string, guest bool) (*cartDto.CartResponse, error) {
+	items, err := useCase.cartRepo.GetCartItems(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	onlineLocation, err := useCase.onlineLocation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	variantIDs := make([]string, len(items))
+	for i, item := range items {
+		variantIDs[i] = item.VariantID
+	}
+	available, err := useCase.inventoryRepo.GetAvailableQuantities(ctx, onlineLocation.ID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := useCase.cartRepo.RepriceItems(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	view := &cartDto.CartResponse{ID: cartID, Items: make([]*cartDto.CartLineResponse, len(items)), UpdatedAt: time.Now()}
+	for i, item := range items {
+		line := &cartDto.CartLineResponse{
+			VariantID:   item.VariantID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			ProductSlug: item.ProductSlug,
+			SKU:         item.SKU,
+			Options:     item.Options,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.CurrentPrice,
+			LineTotal:   item.CurrentPrice * int64(item.Quantity),
+			Available:   available[item.VariantID],
+		}
+		if item.UnitPrice != item.CurrentPrice {
+			previousPrice := item.UnitPrice
+			line.PreviousPrice = &previousPrice
+			line.PriceChanged = true
+			view.HasPriceChanges = true
+		}
+		line.Unavailable = !item.Purchasable || line.Available == 0
+		line.InsufficientStock = !line.Unavailable && line.Quantity > line.Available
+
+		if line.Unavailable || line.InsufficientStock {
+			view.HasUnavailableItems = true
+		}
+		if !line.Unavailable {
+			view.ItemCount += line.Quantity
+			view.Subtotal += line.LineTotal
+		}
+		view.Items[i] = line
+	}
+
+	if guest {
+		view.CartToken, err = middleware.GeneratePurposeToken(cartID, "", entity.TokenPurposeCart, useCase.config.CartConfig.GuestCartLifeTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return view, nil
+}
+
+// onlineLocation is where online carts are served from, a missing one is a configuration error rather than a 404
+func (useCase *CartUC) onlineLocation(ctx context.Context) (*entity.Location, error) {
+	code := useCase.config.InventoryConfig.OnlineLocationCode
+	l, err := useCase.locationRepo.GetLocationByCode(ctx, code)
+	if errors.Is(err, location.ErrLocationNotFound) {
+		return nil, errors.New("online location " + code + " does not exist")
+	}
+
+	return l, err
+}
@@ -0,0 +1,197 @@
+package clientDelivery
+
+import (
+	"clean-architecture/model/dto/clientDto"
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/client"
+	"clean-architecture/src/user"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type clientDelivery struct {
+	clientUC client.ClientUseCase
+	userUC   user.UserUseCase
+}
+
+func NewClientDelivery(v1Group *gin.RouterGroup, clientUC client.ClientUseCase, userUC user.UserUseCase) {
+	handler := clientDelivery{
+		clientUC: clientUC,
+		userUC:   userUC,
+	}
+
+	oauthGroup := v1Group.Group("/oauth")
+	{
+		oauthGroup.POST("/token", middleware.ClientAuth(clientUC), handler.issueToken)
+	}
+
+	adminGroup := v1Group.Group("/clients", middleware.JwtAuth(userUC), middleware.RequirePermission(entity.PermissionClientManage))
+	{
+		adminGroup.GET("", handler.getClients)
+		adminGroup.GET("/:id", handler.getClientByID)
+		adminGroup.POST("", handler.createClient)
+		adminGroup.PUT("/:id", handler.updateClient)
+		adminGroup.POST("/:id/rotate-secret", handler.rotateClientSecret)
+		adminGroup.DELETE("/:id", handler.revokeClient)
+	}
+}
+
+// issueToken implements the RFC 6749 token endpoint, so its responses use the oauth error shape
+func (c *clientDelivery) issueToken(ctx *gin.Context) {
+	var tokenPayload clientDto.TokenRequest
+	if err := ctx.ShouldBind(&tokenPayload); err != nil {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	}
+
+	oauthClient := ctx.MustGet("client").(*entity.Client)
+	if tokenPayload.GrantType != entity.GrantTypePassword && tokenPayload.GrantType != entity.GrantTypeClientCredentials {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+	if !oauthClient.AllowsGrant(tokenPayload.GrantType) {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "unauthorized_client", client.ErrUnauthorizedGrant.Error())
+		return
+	}
+
+	scope, err := c.clientUC.ResolveScope(oauthClient, tokenPayload.Scope)
+	if err != nil {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	}
+
+	if tokenPayload.GrantType == entity.GrantTypeClientCredentials {
+		token, err := c.clientUC.IssueClientToken(oauthClient, scope)
+		if err != nil {
+			json.AbortWithError(ctx, err, "27")
+			return
+		}
+
+		json.NewResponseOAuthToken(ctx, token)
+		return
+	}
+
+	if tokenPayload.Username == "" || tokenPayload.Password == "" {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_request", "username and password are required")
+		return
+	}
+
+	loginUser, err := c.userUC.Login(ctx.Request.Context(), tokenPayload.Username, tokenPayload.Password, ctx.ClientIP())
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) || errors.Is(err, user.ErrLoginLocked) {
+			json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+		json.AbortWithError(ctx, err, "27")
+		return
+	}
+
+	if err := c.userUC.CheckLoginAllowed(loginUser); err != nil {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+
+	// the password grant has no second step, accounts with totp must use the two step login
+	if loginUser.MfaEnabledAt != nil {
+		json.NewResponseOAuthError(ctx, http.StatusBadRequest, "invalid_grant", "two-factor authentication required, use /users/login")
+		return
+	}
+
+	userToken, err := c.userUC.IssueTokens(ctx.Request.Context(), loginUser, false)
+	if err != nil {
+		json.AbortWithError(ctx, err, "27")
+		return
+	}
+
+	json.NewResponseOAuthToken(ctx, &clientDto.OAuthTokenResponse{
+		AccessToken:  userToken.AccessToken,
+		TokenType:    userToken.TokenType,
+		ExpiresIn:    userToken.ExpiresIn,
+		RefreshToken: userToken.RefreshToken,
+		Scope:        scope,
+	})
+}
+
+func (c *clientDelivery) getClients(ctx *gin.Context) {
+	clients, err := c.clientUC.GetClients(ctx.Request.Context())
+	if err != nil {
+		json.AbortWithError(ctx, err, "21")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, clients, "success", "21", "02")
+}
+
+func (c *clientDelivery) getClientByID(ctx *gin.Context) {
+	ID := ctx.Param("id")
+
+	oauthClient, err := c.clientUC.GetClientByID(ctx.Request.Context(), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "22")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, oauthClient, "success", "22", "03")
+}
+
+func (c *clientDelivery) createClient(ctx *gin.Context) {
+	var clientPayload *clientDto.CreateClientRequest
+	if validationError := validation.BindJSON(ctx, &clientPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "23", "01")
+		return
+	}
+
+	created, err := c.clientUC.CreateClient(ctx.Request.Context(), clientPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "23")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, created, "success", "23", "03")
+}
+
+func (c *clientDelivery) updateClient(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var clientPayload *clientDto.UpdateClientRequest
+	if validationError := validation.BindJSON(ctx, &clientPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "24", "01")
+		return
+	}
+
+	err := c.clientUC.UpdateClient(ctx.Request.Context(), ID, clientPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "24")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "24", "04")
+}
+
+func (c *clientDelivery) rotateClientSecret(ctx *gin.Context) {
+	ID := ctx.Param("id")
+
+	rotated, err := c.clientUC.RotateClientSecret(ctx.Request.Context(), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "25")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, rotated, "success", "25", "03")
+}
+
+func (c *clientDelivery) revokeClient(ctx *gin.Context) {
+	ID := ctx.Param("id")
+
+	err := c.clientUC.RevokeClient(ctx.Request.Context(), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "26")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "26", "03")
+}
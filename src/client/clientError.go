@@ -0,0 +1,9 @@
+package client
+
+import "clean-architecture/pkg/domainError"
+
+var (
+	ErrClientNotFound    = domainError.NotFound("client not found")
+	ErrUnauthorizedGrant = domainError.Validation("grant type not allowed for this client")
+	ErrInvalidScope      = domainError.Validation("requested scope exceeds the client scopes")
+)
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"clean-architecture/model/dto/clientDto"
+	"clean-architecture/model/entity"
+	"context"
+)
+
+type ClientRepository interface {
+	CreateClient(ctx context.Context, client *entity.Client) (string, error)
+	GetClientByID(ctx context.Context, id string) (*entity.Client, error)
+	GetClientByClientID(ctx context.Context, clientID string) (*entity.Client, error)
+	GetClients(ctx context.Context) ([]*entity.Client, error)
+	UpdateClient(ctx context.Context, client *entity.Client) error
+	UpdateClientSecret(ctx context.Context, id, secretHash string) error
+	RevokeClient(ctx context.Context, id string) error
+}
+
+type ClientUseCase interface {
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.Client, bool, error)
+	CreateClient(ctx context.Context, client *clientDto.CreateClientRequest) (*clientDto.ClientSecretResponse, error)
+	GetClientByID(ctx context.Context, id string) (*entity.Client, error)
+	GetClients(ctx context.Context) ([]*entity.Client, error)
+	UpdateClient(ctx context.Context, id string, client *clientDto.UpdateClientRequest) error
+	RotateClientSecret(ctx context.Context, id string) (*clientDto.ClientSecretResponse, error)
+	RevokeClient(ctx context.Context, id string) error
+	ResolveScope(client *entity.Client, requested string) (string, error)
+	IssueClientToken(client *entity.Client, scope string) (*clientDto.OAuthTokenResponse, error)
+}
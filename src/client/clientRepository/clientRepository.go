@@ -0,0 +1,142 @@
+package clientRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/client"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type clientRepository struct {
+	db *sql.DB
+}
+
+func NewClientRepository(db *sql.DB) client.ClientRepository {
+	return &clientRepository{db}
+}
+
+func (repo *clientRepository) CreateClient(ctx context.Context, client *entity.Client) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO clients (client_id, name, secret_hash, scopes, grant_types) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, client.ClientID, client.Name, client.SecretHash,
+		pq.Array(client.Scopes), pq.Array(client.GrantTypes)).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *clientRepository) GetClientByID(ctx context.Context, id string) (*entity.Client, error) {
+	sqlQuery := `SELECT id, client_id, name, secret_hash, scopes, grant_types, created_at, updated_at, revoked_at
+		FROM clients WHERE id = $1`
+	c, err := scanClient(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, clientNotFound(err)
+	}
+
+	return c, nil
+}
+
+// GetClientByClientID only finds clients that are not revoked
+func (repo *clientRepository) GetClientByClientID(ctx context.Context, clientID string) (*entity.Client, error) {
+	sqlQuery := `SELECT id, client_id, name, secret_hash, scopes, grant_types, created_at, updated_at, revoked_at
+		FROM clients WHERE client_id = $1 AND revoked_at IS NULL`
+	c, err := scanClient(repo.db.QueryRowContext(ctx, sqlQuery, clientID))
+	if err != nil {
+		return nil, clientNotFound(err)
+	}
+
+	return c, nil
+}
+
+func (repo *clientRepository) GetClients(ctx context.Context) ([]*entity.Client, error) {
+	sqlQuery := `SELECT id, client_id, name, secret_hash, scopes, grant_types, created_at, updated_at, revoked_at
+		FROM clients ORDER BY created_at`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []*entity.Client
+	for rows.Next() {
+		c, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+
+	return clients, rows.Err()
+}
+
+func (repo *clientRepository) UpdateClient(ctx context.Context, client *entity.Client) error {
+	query := `UPDATE clients SET name = $2, scopes = $3, grant_types = $4, updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL`
+	return execAffectingOne(ctx, repo.db, query, client.ID, client.Name, pq.Array(client.Scopes), pq.Array(client.GrantTypes))
+}
+
+func (repo *clientRepository) UpdateClientSecret(ctx context.Context, id, secretHash string) error {
+	query := "UPDATE clients SET secret_hash = $2, updated_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+	return execAffectingOne(ctx, repo.db, query, id, secretHash)
+}
+
+func (repo *clientRepository) RevokeClient(ctx context.Context, id string) error {
+	query := "UPDATE clients SET revoked_at = NOW(), updated_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+	return execAffectingOne(ctx, repo.db, query, id)
+}
+
+// execAffectingOne reports ErrClientNotFound when the statement matched nothing
+func execAffectingOne(ctx context.Context, db *sql.DB, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return clientNotFound(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return client.ErrClientNotFound
+	}
+
+	return nil
+}
+
+// clientNotFound maps a missing row, or an id that is not a valid uuid, to ErrClientNotFound
+func clientNotFound(err error) error {
+	var pqErr *pq.Error
+	if err == sql.ErrNoRows || (errors.As(err, &pqErr) && pqErr.Code == "22P02") {
+		return client.ErrClientNotFound
+	}
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanClient(row rowScanner) (*entity.Client, error) {
+	c := new(entity.Client)
+	err := row.Scan(
+		&c.ID,
+		&c.ClientID,
+		&c.Name,
+		&c.SecretHash,
+		pq.Array(&c.Scopes),
+		pq.Array(&c.GrantTypes),
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,155 @@
+package clientUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/clientDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/src/client"
+	"clean-architecture/utils"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+type ClientUC struct {
+	clientRepo client.ClientRepository
+	config     dto.ConfigData
+}
+
+func NewClientUseCase(clientRepo client.ClientRepository, config dto.ConfigData) client.ClientUseCase {
+	return &ClientUC{clientRepo, config}
+}
+
+// AuthenticateClient compares secret hashes in constant time, unknown and revoked clients simply fail
+func (useCase *ClientUC) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.Client, bool, error) {
+	c, err := useCase.clientRepo.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, client.ErrClientNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	presented := utils.HashToken(clientSecret)
+	if subtle.ConstantTimeCompare([]byte(presented), []byte(c.SecretHash)) != 1 {
+		return nil, false, nil
+	}
+
+	return c, true, nil
+}
+
+// CreateClient registers a client and returns its secret, which is not recoverable afterwards
+func (useCase *ClientUC) CreateClient(ctx context.Context, newClient *clientDto.CreateClientRequest) (*clientDto.ClientSecretResponse, error) {
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &entity.Client{
+		ClientID:   clientID,
+		Name:       newClient.Name,
+		SecretHash: utils.HashToken(secret),
+		Scopes:     nonNil(newClient.Scopes),
+		GrantTypes: newClient.GrantTypes,
+	}
+	c.ID, err = useCase.clientRepo.CreateClient(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := useCase.clientRepo.GetClientByID(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientDto.ClientSecretResponse{Client: created, ClientSecret: secret}, nil
+}
+
+func (useCase *ClientUC) GetClientByID(ctx context.Context, id string) (*entity.Client, error) {
+	return useCase.clientRepo.GetClientByID(ctx, id)
+}
+
+func (useCase *ClientUC) GetClients(ctx context.Context) ([]*entity.Client, error) {
+	return useCase.clientRepo.GetClients(ctx)
+}
+
+func (useCase *ClientUC) UpdateClient(ctx context.Context, id string, updated *clientDto.UpdateClientRequest) error {
+	return useCase.clientRepo.UpdateClient(ctx, &entity.Client{
+		ID:         id,
+		Name:       updated.Name,
+		Scopes:     nonNil(updated.Scopes),
+		GrantTypes: updated.GrantTypes,
+	})
+}
+
+// RotateClientSecret replaces the secret immediately, the previous one stops working at once
+func (useCase *ClientUC) RotateClientSecret(ctx context.Context, id string) (*clientDto.ClientSecretResponse, error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := useCase.clientRepo.UpdateClientSecret(ctx, id, utils.HashToken(secret)); err != nil {
+		return nil, err
+	}
+
+	rotated, err := useCase.clientRepo.GetClientByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientDto.ClientSecretResponse{Client: rotated, ClientSecret: secret}, nil
+}
+
+func (useCase *ClientUC) RevokeClient(ctx context.Context, id string) error {
+	return useCase.clientRepo.RevokeClient(ctx, id)
+}
+
+// ResolveScope checks a space separated scope request against the client, an empty request grants every client scope
+func (useCase *ClientUC) ResolveScope(c *entity.Client, requested string) (string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return strings.Join(c.Scopes, " "), nil
+	}
+
+	for _, scope := range scopes {
+		if !c.AllowsScope(scope) {
+			return "", client.ErrInvalidScope
+		}
+	}
+
+	return strings.Join(scopes, " "), nil
+}
+
+func (useCase *ClientUC) IssueClientToken(c *entity.Client, scope string) (*clientDto.OAuthTokenResponse, error) {
+	if !c.AllowsGrant(entity.GrantTypeClientCredentials) {
+		return nil, client.ErrUnauthorizedGrant
+	}
+
+	lifeTime := useCase.config.JwtConfig.AccessTokenLifeTime
+	token, err := middleware.GenerateClientTokenJwt(c.ClientID, scope, lifeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clientDto.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifeTime.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,230 @@
+package inventoryDelivery
+
+import (
+	"clean-architecture/model/dto/inventoryDto"
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/inventory"
+	"clean-architecture/utils"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type inventoryDelivery struct {
+	inventoryUC inventory.InventoryUseCase
+}
+
+func NewInventoryDelivery(v1Group *gin.RouterGroup, inventoryUC inventory.InventoryUseCase, jwtAuth gin.HandlerFunc) {
+	handler := inventoryDelivery{
+		inventoryUC: inventoryUC,
+	}
+
+	readGroup := v1Group.Group("/inventory", jwtAuth, middleware.RequirePermission(entity.PermissionInventoryRead))
+	{
+		readGroup.GET("/stock", handler.getStockLevels)
+		readGroup.GET("/variants/:id", handler.getVariantStock)
+		readGroup.GET("/variants/:id/movements", handler.getMovements)
+		readGroup.GET("/transfers", handler.getTransfers)
+		readGroup.GET("/transfers/:id", handler.getTransferByID)
+	}
+
+	manageGroup := v1Group.Group("/inventory", jwtAuth, middleware.RequirePermission(entity.PermissionInventoryManage))
+	{
+		manageGroup.POST("/adjustments", handler.adjustStock)
+		manageGroup.POST("/receipts", handler.receiveStock)
+		manageGroup.POST("/transfers", handler.createTransfer)
+		manageGroup.POST("/transfers/:id/ship", handler.shipTransfer)
+		manageGroup.POST("/transfers/:id/receive", handler.receiveTransfer)
+		manageGroup.POST("/transfers/:id/cancel", handler.cancelTransfer)
+	}
+}
+
+func (c *inventoryDelivery) getVariantStock(ctx *gin.Context) {
+	stock, err := c.inventoryUC.GetVariantStock(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "65")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, stock, "success", "65", "01")
+}
+
+func (c *inventoryDelivery) getMovements(ctx *gin.Context) {
+	page, size, validationError := parsePageQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "66", "01")
+		return
+	}
+
+	query := &inventoryDto.MovementListQuery{VariantID: ctx.Param("id"), LocationID: ctx.Query("location_id"), Page: page, Size: size}
+	movements, total, err := c.inventoryUC.GetMovements(ctx.Request.Context(), query)
+	if err != nil {
+		json.AbortWithError(ctx, err, "66")
+		return
+	}
+
+	json.NewResponseSuccessPage(ctx, movements, json.NewPaging(page, size, total, ""), "success", "66", "02")
+}
+
+func (c *inventoryDelivery) getStockLevels(ctx *gin.Context) {
+	page, size, validationError := parsePageQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "75", "01")
+		return
+	}
+
+	query := &inventoryDto.StockListQuery{LocationID: ctx.Query("location_id"), SKU: ctx.Query("sku"), Page: page, Size: size}
+	levels, total, err := c.inventoryUC.GetStockLevels(ctx.Request.Context(), query)
+	if err != nil {
+		json.AbortWithError(ctx, err, "75")
+		return
+	}
+
+	json.NewResponseSuccessPage(ctx, levels, json.NewPaging(page, size, total, ""), "success", "75", "02")
+}
+
+func (c *inventoryDelivery) adjustStock(ctx *gin.Context) {
+	var adjustmentPayload *inventoryDto.AdjustmentRequest
+	if validationError := validation.BindJSON(ctx, &adjustmentPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "67", "01")
+		return
+	}
+
+	movement, err := c.inventoryUC.AdjustStock(ctx.Request.Context(), middleware.GetActor(ctx).ID, adjustmentPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "67")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, movement, "success", "67", "02")
+}
+
+func (c *inventoryDelivery) receiveStock(ctx *gin.Context) {
+	var receiptPayload *inventoryDto.ReceiptRequest
+	if validationError := validation.BindJSON(ctx, &receiptPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "68", "01")
+		return
+	}
+
+	movement, err := c.inventoryUC.ReceiveStock(ctx.Request.Context(), middleware.GetActor(ctx).ID, receiptPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "68")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, movement, "success", "68", "02")
+}
+
+func (c *inventoryDelivery) createTransfer(ctx *gin.Context) {
+	var transferPayload *inventoryDto.TransferRequest
+	if validationError := validation.BindJSON(ctx, &transferPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "76", "01")
+		return
+	}
+
+	transfer, err := c.inventoryUC.CreateTransfer(ctx.Request.Context(), middleware.GetActor(ctx).ID, transferPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "76")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, transfer, "success", "76", "02")
+}
+
+func (c *inventoryDelivery) getTransfers(ctx *gin.Context) {
+	page, size, validationError := parsePageQuery(ctx)
+	status := entity.TransferStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		validationError = append(validationError, json.ValidationField{FieldName: "status", Message: "must be one of requested in_transit received cancelled"})
+	}
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "77", "01")
+		return
+	}
+
+	query := &inventoryDto.TransferListQuery{Status: status, LocationID: ctx.Query("location_id"), Page: page, Size: size}
+	transfers, total, err := c.inventoryUC.GetTransfers(ctx.Request.Context(), query)
+	if err != nil {
+		json.AbortWithError(ctx, err, "77")
+		return
+	}
+
+	json.NewResponseSuccessPage(ctx, transfers, json.NewPaging(page, size, total, ""), "success", "77", "02")
+}
+
+func (c *inventoryDelivery) getTransferByID(ctx *gin.Context) {
+	transfer, err := c.inventoryUC.GetTransferByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "78")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, transfer, "success", "78", "01")
+}
+
+func (c *inventoryDelivery) shipTransfer(ctx *gin.Context) {
+	transfer, err := c.inventoryUC.ShipTransfer(ctx.Request.Context(), middleware.GetActor(ctx).ID, ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "79")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, transfer, "success", "79", "01")
+}
+
+func (c *inventoryDelivery) receiveTransfer(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	// an empty body confirms that everything arrived as shipped
+	receivePayload := &inventoryDto.ReceiveTransferRequest{}
+	if ctx.Request.ContentLength != 0 {
+		if validationError := validation.BindJSON(ctx, &receivePayload); len(validationError) > 0 {
+			json.NewResponseBadRequest(ctx, validationError, "bad request", "80", "01")
+			return
+		}
+	}
+
+	transfer, err := c.inventoryUC.ReceiveTransfer(ctx.Request.Context(), middleware.GetActor(ctx).ID, ID, receivePayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "80")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, transfer, "success", "80", "02")
+}
+
+func (c *inventoryDelivery) cancelTransfer(ctx *gin.Context) {
+	transfer, err := c.inventoryUC.CancelTransfer(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "81")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, transfer, "success", "81", "01")
+}
+
+// parsePageQuery reads page and size, defaulting to the first page of DefaultPageSize
+func parsePageQuery(ctx *gin.Context) (int, int, []json.ValidationField) {
+	var validationError []json.ValidationField
+	page, size := 1, inventoryDto.DefaultPageSize
+
+	if value := ctx.Query("page"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 {
+			validationError = append(validationError, json.ValidationField{FieldName: "page", Message: "must be a positive number"})
+		}
+		page = parsed
+	}
+
+	if value := ctx.Query("size"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 || parsed > inventoryDto.MaxPageSize {
+			validationError = append(validationError, json.ValidationField{FieldName: "size", Message: fmt.Sprintf("must be between 1 and %d", inventoryDto.MaxPageSize)})
+		}
+		size = parsed
+	}
+
+	return page, size, validationError
+}
@@ -0,0 +1,31 @@
+package inventory
+
+import (
+	"clean-architecture/pkg/domainError"
+	"strings"
+)
+
+var (
+	ErrTransferNotFound = domainError.NotFound("transfer not found")
+
+	ErrInsufficientStock = domainError.Conflict("insufficient stock")
+	ErrTransferStatus    = domainError.Conflict("transfer is not in a status that allows this")
+
+	ErrInvalidMovementAmount = domainError.Validation("bad request", domainError.Field{Name: "quantity", Message: "has the wrong sign for this movement type"})
+	ErrSameLocation          = domainError.Validation("bad request", domainError.Field{Name: "to_location_id", Message: "must differ from from_location_id"})
+	ErrDuplicateTransferLine = domainError.Validation("bad request", domainError.Field{Name: "lines", Message: "each variant may appear only once"})
+	ErrUnknownTransferLine   = domainError.Validation("bad request", domainError.Field{Name: "lines", Message: "only variants of the transfer can be received"})
+)
+
+// InsufficientStockError names every variant an allocation came up short on, it unwraps to ErrInsufficientStock
+type InsufficientStockError struct {
+	VariantIDs []string
+}
+
+func (e *InsufficientStockError) Error() string {
+	return "insufficient stock for variants " + strings.Join(e.VariantIDs, ", ")
+}
+
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock
+}
@@ -0,0 +1,47 @@
+package inventory
+
+import (
+	"clean-architecture/model/dto/inventoryDto"
+	"clean-architecture/model/entity"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type InventoryRepository interface {
+	RecordMovement(ctx context.Context, movement *entity.StockMovement) (string, error)
+	GetMovementByID(ctx context.Context, id string) (*entity.StockMovement, error)
+	GetMovements(ctx context.Context, query *inventoryDto.MovementListQuery) ([]*entity.StockMovement, int, error)
+	GetVariantStockLevels(ctx context.Context, variantID string) ([]*entity.StockLevel, error)
+	GetStockLevels(ctx context.Context, query *inventoryDto.StockListQuery) ([]*entity.StockLevel, int, error)
+	GetAvailableQuantities(ctx context.Context, locationID string, variantIDs []string) (map[string]int, error)
+	AllocateStock(ctx context.Context, tx *sql.Tx, allocation *entity.StockAllocation) error
+	HoldStock(ctx context.Context, reference, locationID string, lines []entity.StockLine, lifeTime time.Duration) error
+	GetReservations(ctx context.Context, reference string) ([]*entity.Reservation, error)
+	ReleaseReservations(ctx context.Context, reference string) error
+	ExpireReservations(ctx context.Context) (int, error)
+	CreateTransfer(ctx context.Context, transfer *entity.StockTransfer) (string, error)
+	GetTransferByID(ctx context.Context, id string) (*entity.StockTransfer, error)
+	GetTransfers(ctx context.Context, query *inventoryDto.TransferListQuery) ([]*entity.StockTransfer, int, error)
+	ShipTransfer(ctx context.Context, id, shippedBy string) error
+	ReceiveTransfer(ctx context.Context, id, receivedBy string, received []*entity.TransferLine) error
+	CancelTransfer(ctx context.Context, id string) error
+}
+
+type InventoryUseCase interface {
+	ReceiveStock(ctx context.Context, actorID string, request *inventoryDto.ReceiptRequest) (*entity.StockMovement, error)
+	AdjustStock(ctx context.Context, actorID string, request *inventoryDto.AdjustmentRequest) (*entity.StockMovement, error)
+	RecordMovement(ctx context.Context, movement *entity.StockMovement) (*entity.StockMovement, error)
+	GetVariantStock(ctx context.Context, variantID string) (*inventoryDto.VariantStockResponse, error)
+	GetStockLevels(ctx context.Context, query *inventoryDto.StockListQuery) ([]*entity.StockLevel, int, error)
+	GetMovements(ctx context.Context, query *inventoryDto.MovementListQuery) ([]*entity.StockMovement, int, error)
+	Reserve(ctx context.Context, reference, locationID string, lines []entity.StockLine) ([]*entity.Reservation, error)
+	ReleaseReservations(ctx context.Context, reference string) error
+	ExpireReservations(ctx context.Context) (int, error)
+	CreateTransfer(ctx context.Context, actorID string, request *inventoryDto.TransferRequest) (*entity.StockTransfer, error)
+	GetTransferByID(ctx context.Context, id string) (*entity.StockTransfer, error)
+	GetTransfers(ctx context.Context, query *inventoryDto.TransferListQuery) ([]*entity.StockTransfer, int, error)
+	ShipTransfer(ctx context.Context, actorID, id string) (*entity.StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, actorID, id string, request *inventoryDto.ReceiveTransferRequest) (*entity.StockTransfer, error)
+	CancelTransfer(ctx context.Context, id string) (*entity.StockTransfer, error)
+}
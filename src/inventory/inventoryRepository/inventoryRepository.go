@@ -0,0 +1,515 @@
+package inventoryRepository
+
+import (
+	"clean-architecture/model/dto/inventoryDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/variant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type inventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) inventory.InventoryRepository {
+	return &inventoryRepository{db}
+}
+
+const movementColumns = `id, location_id, variant_id, type, quantity, reason_code, reference, note, created_by, created_at`
+
+// RecordMovement appends a movement to the ledger and applies it to the cached on-hand total in one transaction.
+// Stock may never go below zero, and a sale may not take stock that is held by a reservation.
+func (repo *inventoryRepository) RecordMovement(ctx context.Context, movement *entity.StockMovement) (string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	onHand, err := lockStockLevel(ctx, tx, movement.LocationID, movement.VariantID)
+	if err != nil {
+		return "", err
+	}
+
+	limit := onHand
+	if movement.Type == entity.MovementSale {
+		reserved, err := reservedQuantity(ctx, tx, movement.LocationID, movement.VariantID, "")
+		if err != nil {
+			return "", err
+		}
+		limit -= reserved
+	}
+	if movement.Quantity < 0 && limit+movement.Quantity < 0 {
+		return "", inventory.ErrInsufficientStock
+	}
+
+	id, err := insertMovement(ctx, tx, movement)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *inventoryRepository) GetMovementByID(ctx context.Context, id string) (*entity.StockMovement, error) {
+	movement, err := scanMovement(repo.db.QueryRowContext(ctx, `SELECT `+movementColumns+` FROM stock_movements WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// GetMovements returns one page of the ledger of a variant, newest first, optionally at one location
+func (repo *inventoryRepository) GetMovements(ctx context.Context, query *inventoryDto.MovementListQuery) ([]*entity.StockMovement, int, error) {
+	args := []interface{}{query.VariantID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := " WHERE variant_id = $1"
+	if query.LocationID != "" {
+		where += " AND location_id = " + arg(query.LocationID)
+	}
+
+	var total int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sqlQuery := "SELECT " + movementColumns + " FROM stock_movements" + where +
+		" ORDER BY created_at DESC, id DESC LIMIT " + arg(query.Size) + " OFFSET " + arg((query.Page-1)*query.Size)
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []*entity.StockMovement{}
+	for rows.Next() {
+		movement, err := scanMovement(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, rows.Err()
+}
+
+// stockLevelQuery reads stock levels with the live reservations of each location counted against them
+const stockLevelQuery = `SELECT l.location_id, loc.code, l.variant_id, v.sku, l.on_hand, COALESCE(r.reserved, 0), l.updated_at
+	FROM stock_levels l
+	JOIN locations loc ON loc.id = l.location_id
+	JOIN product_variants v ON v.id = l.variant_id
+	LEFT JOIN LATERAL (
+		SELECT SUM(quantity) AS reserved FROM stock_reservations sr
+		WHERE sr.location_id = l.location_id AND sr.variant_id = l.variant_id
+			AND sr.status = 'active' AND sr.expires_at > NOW()
+	) r ON TRUE`
+
+// GetVariantStockLevels returns the stock of a variant at every location that ever held it
+func (repo *inventoryRepository) GetVariantStockLevels(ctx context.Context, variantID string) ([]*entity.StockLevel, error) {
+	rows, err := repo.db.QueryContext(ctx, stockLevelQuery+" WHERE l.variant_id = $1 ORDER BY loc.code", variantID)
+	if err != nil {
+		return nil, notFound(err, variant.ErrVariantNotFound)
+	}
+	defer rows.Close()
+
+	return scanStockLevels(rows)
+}
+
+// GetStockLevels is the stock report, one row per SKU per location ordered by SKU
+func (repo *inventoryRepository) GetStockLevels(ctx context.Context, query *inventoryDto.StockListQuery) ([]*entity.StockLevel, int, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.LocationID != "" {
+		conditions = append(conditions, "l.location_id = "+arg(query.LocationID))
+	}
+	if query.SKU != "" {
+		conditions = append(conditions, "v.sku = "+arg(query.SKU))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM stock_levels l
+		JOIN product_variants v ON v.id = l.variant_id`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx, stockLevelQuery+where+
+		" ORDER BY v.sku, loc.code LIMIT "+arg(query.Size)+" OFFSET "+arg((query.Page-1)*query.Size), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	levels, err := scanStockLevels(rows)
+	return levels, total, err
+}
+
+// GetAvailableQuantities returns what can still be sold of each variant at a location, variants never stocked there are zero
+func (repo *inventoryRepository) GetAvailableQuantities(ctx context.Context, locationID string, variantIDs []string) (map[string]int, error) {
+	available := make(map[string]int, len(variantIDs))
+	for _, variantID := range variantIDs {
+		available[variantID] = 0
+	}
+	if len(variantIDs) == 0 {
+		return available, nil
+	}
+
+	rows, err := repo.db.QueryContext(ctx, stockLevelQuery+" WHERE l.location_id = $1 AND l.variant_id = ANY($2)", locationID, pq.Array(variantIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels, err := scanStockLevels(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, level := range levels {
+		available[level.VariantID] = level.Available
+	}
+
+	return available, nil
+}
+
+func scanStockLevels(rows *sql.Rows) ([]*entity.StockLevel, error) {
+	levels := []*entity.StockLevel{}
+	for rows.Next() {
+		level := new(entity.StockLevel)
+		err := rows.Scan(
+			&level.LocationID,
+			&level.LocationCode,
+			&level.VariantID,
+			&level.SKU,
+			&level.OnHand,
+			&level.Reserved,
+			&level.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		level.Available = level.OnHand - level.Reserved
+		if level.Available < 0 {
+			level.Available = 0
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, rows.Err()
+}
+
+// HoldStock reserves the lines at a location for a checkout, all of them or none. A new hold under the same
+// reference replaces the previous one, and it is taken under the stock level locks so two checkouts can never
+// hold the same unit. Like every path touching reservations, they are locked before the stock levels.
+func (repo *inventoryRepository) HoldStock(ctx context.Context, reference, locationID string, lines []entity.StockLine, lifeTime time.Duration) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockHold(ctx, tx, reference); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE stock_reservations SET status = 'released', released_at = NOW()
+		WHERE reference = $1 AND status = 'active'`, reference)
+	if err != nil {
+		return err
+	}
+
+	lines = sortedLines(lines)
+	var short []string
+	for _, line := range lines {
+		onHand, err := lockStockLevel(ctx, tx, locationID, line.VariantID)
+		if err != nil {
+			return err
+		}
+		reserved, err := reservedQuantity(ctx, tx, locationID, line.VariantID, "")
+		if err != nil {
+			return err
+		}
+		if onHand-reserved < line.Quantity {
+			short = append(short, line.VariantID)
+		}
+	}
+	if len(short) > 0 {
+		return &inventory.InsufficientStockError{VariantIDs: short}
+	}
+
+	for _, line := range lines {
+		_, err := tx.ExecContext(ctx, `INSERT INTO stock_reservations (location_id, variant_id, quantity, reference, expires_at)
+			VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))`,
+			locationID, line.VariantID, line.Quantity, reference, lifeTime.Seconds())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReservations returns the live reservations held under a reference
+func (repo *inventoryRepository) GetReservations(ctx context.Context, reference string) ([]*entity.Reservation, error) {
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, location_id, variant_id, quantity, reference, status, expires_at, created_at
+		FROM stock_reservations WHERE reference = $1 AND status = 'active' AND expires_at > NOW()
+		ORDER BY variant_id`, reference)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []*entity.Reservation{}
+	for rows.Next() {
+		reservation := new(entity.Reservation)
+		err := rows.Scan(
+			&reservation.ID,
+			&reservation.LocationID,
+			&reservation.VariantID,
+			&reservation.Quantity,
+			&reservation.Reference,
+			&reservation.Status,
+			&reservation.ExpiresAt,
+			&reservation.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+// ReleaseReservations gives back the stock held under a reference, releasing reservations that already ended is a no-op
+func (repo *inventoryRepository) ReleaseReservations(ctx context.Context, reference string) error {
+	_, err := repo.db.ExecContext(ctx, `UPDATE stock_reservations SET status = 'released', released_at = NOW()
+		WHERE reference = $1 AND status = 'active'`, reference)
+	return err
+}
+
+// AllocateStock books the lines of an allocation as sales inside the transaction of the caller, which lets an
+// order and the stock it takes commit together. Stock held under the hold reference of the allocation counts as
+// available to it, the hold is consumed for the allocated variants and released for the rest. Every line is
+// checked before failing, so an InsufficientStockError names all variants that ran short, not just the first.
+func (repo *inventoryRepository) AllocateStock(ctx context.Context, tx *sql.Tx, allocation *entity.StockAllocation) error {
+	if allocation.HoldReference != "" {
+		if err := lockHold(ctx, tx, allocation.HoldReference); err != nil {
+			return err
+		}
+	}
+
+	lines := sortedLines(allocation.Lines)
+	var short []string
+	for _, line := range lines {
+		onHand, err := lockStockLevel(ctx, tx, allocation.LocationID, line.VariantID)
+		if err != nil {
+			return err
+		}
+		reserved, err := reservedQuantity(ctx, tx, allocation.LocationID, line.VariantID, allocation.HoldReference)
+		if err != nil {
+			return err
+		}
+		if onHand-reserved < line.Quantity {
+			short = append(short, line.VariantID)
+		}
+	}
+	if len(short) > 0 {
+		return &inventory.InsufficientStockError{VariantIDs: short}
+	}
+
+	variantIDs := make([]string, len(lines))
+	for i, line := range lines {
+		variantIDs[i] = line.VariantID
+		_, err := insertMovement(ctx, tx, &entity.StockMovement{
+			LocationID: allocation.LocationID,
+			VariantID:  line.VariantID,
+			Type:       entity.MovementSale,
+			Quantity:   -line.Quantity,
+			Reference:  allocation.Reference,
+			CreatedBy:  allocation.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if allocation.HoldReference == "" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE stock_reservations
+		SET status = CASE WHEN location_id = $2 AND variant_id = ANY($3) THEN 'consumed' ELSE 'released' END, released_at = NOW()
+		WHERE reference = $1 AND status = 'active' AND expires_at > NOW()`,
+		allocation.HoldReference, allocation.LocationID, pq.Array(variantIDs))
+	return err
+}
+
+// ExpireReservations marks every lapsed reservation as expired, they stopped counting against stock when they lapsed
+func (repo *inventoryRepository) ExpireReservations(ctx context.Context) (int, error) {
+	result, err := repo.db.ExecContext(ctx, `UPDATE stock_reservations SET status = 'expired', released_at = expires_at
+		WHERE status = 'active' AND expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// lockStockLevel creates the stock level row on first use and locks it, every stock change of a variant
+// at a location serializes on it. Callers locking several levels take them in variant id order.
+func lockStockLevel(ctx context.Context, tx *sql.Tx, locationID, variantID string) (int, error) {
+	_, err := tx.ExecContext(ctx, `INSERT INTO stock_levels (location_id, variant_id) VALUES ($1, $2)
+		ON CONFLICT (location_id, variant_id) DO NOTHING`, locationID, variantID)
+	if err != nil {
+		return 0, stockLevelError(err)
+	}
+
+	var onHand int
+	err = tx.QueryRowContext(ctx, `SELECT on_hand FROM stock_levels WHERE location_id = $1 AND variant_id = $2 FOR UPDATE`,
+		locationID, variantID).Scan(&onHand)
+	if err != nil {
+		return 0, err
+	}
+
+	return onHand, nil
+}
+
+// stockLevelError tells which side of a stock level does not exist
+func stockLevelError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation {
+		if pqErr.Constraint == "stock_levels_location_id_fkey" {
+			return location.ErrLocationNotFound
+		}
+		return variant.ErrVariantNotFound
+	}
+	if isPqError(err, pqInvalidTextRepresentation) {
+		return variant.ErrVariantNotFound
+	}
+	return err
+}
+
+// reservedQuantity is the stock live reservations hold, except those under exceptReference when it is set
+func reservedQuantity(ctx context.Context, tx *sql.Tx, locationID, variantID, exceptReference string) (int, error) {
+	var reserved int
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE location_id = $1 AND variant_id = $2 AND status = 'active' AND expires_at > NOW() AND ($3 = '' OR reference <> $3)`,
+		locationID, variantID, exceptReference).Scan(&reserved)
+	return reserved, err
+}
+
+// lockHold locks the live reservations under a reference, before any stock level is locked
+func lockHold(ctx context.Context, tx *sql.Tx, reference string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM stock_reservations WHERE reference = $1 AND status = 'active' FOR UPDATE`, reference)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// sortedLines copies lines into variant id order, the order several stock levels are always locked in
+func sortedLines(lines []entity.StockLine) []entity.StockLine {
+	sorted := make([]entity.StockLine, len(lines))
+	copy(sorted, lines)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].VariantID < sorted[j].VariantID })
+	return sorted
+}
+
+// insertMovement appends to the ledger and moves the cached total, the caller must hold the stock level lock
+func insertMovement(ctx context.Context, tx *sql.Tx, movement *entity.StockMovement) (string, error) {
+	var id string
+	err := tx.QueryRowContext(ctx, `INSERT INTO stock_movements (location_id, variant_id, type, quantity, reason_code, reference, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		movement.LocationID, movement.VariantID, movement.Type, movement.Quantity, movement.ReasonCode,
+		movement.Reference, movement.Note, movement.CreatedBy).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_levels SET on_hand = on_hand + $3, updated_at = NOW() WHERE location_id = $1 AND variant_id = $2`,
+		movement.LocationID, movement.VariantID, movement.Quantity)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqForeignKeyViolation       = "23503"
+	pqCheckViolation            = "23514"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMovement(row rowScanner) (*entity.StockMovement, error) {
+	movement := new(entity.StockMovement)
+	err := row.Scan(
+		&movement.ID,
+		&movement.LocationID,
+		&movement.VariantID,
+		&movement.Type,
+		&movement.Quantity,
+		&movement.ReasonCode,
+		&movement.Reference,
+		&movement.Note,
+		&movement.CreatedBy,
+		&movement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,401 @@
+package inventoryRepository
+
+import (
+	"clean-architecture/model/dto/inventoryDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/variant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const transferColumns = `id, from_location_id, to_location_id, status, note, requested_by, shipped_by, received_by,
+	created_at, shipped_at, received_at, updated_at`
+
+func (repo *inventoryRepository) CreateTransfer(ctx context.Context, transfer *entity.StockTransfer) (string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRowContext(ctx, `INSERT INTO stock_transfers (from_location_id, to_location_id, note, requested_by)
+		VALUES ($1, $2, $3, $4) RETURNING id`,
+		transfer.FromLocationID, transfer.ToLocationID, transfer.Note, transfer.RequestedBy).Scan(&id)
+	if err != nil {
+		return "", transferWriteError(err)
+	}
+
+	for _, line := range transfer.Lines {
+		_, err := tx.ExecContext(ctx, `INSERT INTO stock_transfer_lines (transfer_id, variant_id, quantity_requested) VALUES ($1, $2, $3)`,
+			id, line.VariantID, line.QuantityRequested)
+		if err != nil {
+			return "", transferWriteError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *inventoryRepository) GetTransferByID(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	transfer, err := scanTransfer(repo.db.QueryRowContext(ctx, `SELECT `+transferColumns+` FROM stock_transfers WHERE id = $1`, id))
+	if err != nil {
+		return nil, notFound(err, inventory.ErrTransferNotFound)
+	}
+
+	if err := repo.loadTransferLines(ctx, []*entity.StockTransfer{transfer}); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// GetTransfers returns one page of transfers, newest first, a location filter matches either end of a transfer
+func (repo *inventoryRepository) GetTransfers(ctx context.Context, query *inventoryDto.TransferListQuery) ([]*entity.StockTransfer, int, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = "+arg(query.Status))
+	}
+	if query.LocationID != "" {
+		placeholder := arg(query.LocationID)
+		conditions = append(conditions, "(from_location_id = "+placeholder+" OR to_location_id = "+placeholder+")")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_transfers"+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sqlQuery := "SELECT " + transferColumns + " FROM stock_transfers" + where +
+		" ORDER BY created_at DESC, id DESC LIMIT " + arg(query.Size) + " OFFSET " + arg((query.Page-1)*query.Size)
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transfers := []*entity.StockTransfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := repo.loadTransferLines(ctx, transfers); err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+// ShipTransfer takes the requested quantities out of the source location, they are in transit until received
+func (repo *inventoryRepository) ShipTransfer(ctx context.Context, id, shippedBy string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromLocationID, _, err := lockTransfer(ctx, tx, id, entity.TransferRequested)
+	if err != nil {
+		return err
+	}
+
+	lines, err := transferLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		onHand, err := lockStockLevel(ctx, tx, fromLocationID, line.VariantID)
+		if err != nil {
+			return err
+		}
+		reserved, err := reservedQuantity(ctx, tx, fromLocationID, line.VariantID, "")
+		if err != nil {
+			return err
+		}
+		if onHand-reserved < line.QuantityRequested {
+			return inventory.ErrInsufficientStock
+		}
+
+		_, err = insertMovement(ctx, tx, &entity.StockMovement{
+			LocationID: fromLocationID,
+			VariantID:  line.VariantID,
+			Type:       entity.MovementTransfer,
+			Quantity:   -line.QuantityRequested,
+			Reference:  transferReference(id),
+			CreatedBy:  &shippedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_transfer_lines SET quantity_shipped = quantity_requested WHERE transfer_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_transfers SET status = 'in_transit', shipped_by = $2, shipped_at = NOW(), updated_at = NOW()
+		WHERE id = $1`, id, shippedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReceiveTransfer books what arrived into the destination location. A received line without a quantity
+// arrived exactly as shipped, anything else is kept on the line as a discrepancy.
+func (repo *inventoryRepository) ReceiveTransfer(ctx context.Context, id, receivedBy string, received []*entity.TransferLine) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, toLocationID, err := lockTransfer(ctx, tx, id, entity.TransferInTransit)
+	if err != nil {
+		return err
+	}
+
+	lines, err := transferLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	shipped := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		shipped[line.VariantID] = true
+	}
+	receivedByVariant := make(map[string]*entity.TransferLine, len(received))
+	for _, line := range received {
+		if !shipped[line.VariantID] {
+			return inventory.ErrUnknownTransferLine
+		}
+		receivedByVariant[line.VariantID] = line
+	}
+
+	for _, line := range lines {
+		quantity, note := *line.QuantityShipped, ""
+		if confirmed, ok := receivedByVariant[line.VariantID]; ok {
+			quantity, note = *confirmed.QuantityReceived, confirmed.DiscrepancyNote
+		}
+
+		if quantity > 0 {
+			if _, err := lockStockLevel(ctx, tx, toLocationID, line.VariantID); err != nil {
+				return err
+			}
+			_, err := insertMovement(ctx, tx, &entity.StockMovement{
+				LocationID: toLocationID,
+				VariantID:  line.VariantID,
+				Type:       entity.MovementTransfer,
+				Quantity:   quantity,
+				Reference:  transferReference(id),
+				Note:       note,
+				CreatedBy:  &receivedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE stock_transfer_lines SET quantity_received = $3, discrepancy_note = $4
+			WHERE transfer_id = $1 AND variant_id = $2`, id, line.VariantID, quantity, note)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_transfers SET status = 'received', received_by = $2, received_at = NOW(), updated_at = NOW()
+		WHERE id = $1`, id, receivedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CancelTransfer is only possible before shipping, nothing has left the source yet
+func (repo *inventoryRepository) CancelTransfer(ctx context.Context, id string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := lockTransfer(ctx, tx, id, entity.TransferRequested); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_transfers SET status = 'cancelled', updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockTransfer locks a transfer that has to be in the expected status and returns its two locations
+func lockTransfer(ctx context.Context, tx *sql.Tx, id string, expected entity.TransferStatus) (string, string, error) {
+	var (
+		fromLocationID, toLocationID string
+		status                       entity.TransferStatus
+	)
+	err := tx.QueryRowContext(ctx, `SELECT from_location_id, to_location_id, status FROM stock_transfers WHERE id = $1 FOR UPDATE`, id).
+		Scan(&fromLocationID, &toLocationID, &status)
+	if err != nil {
+		return "", "", notFound(err, inventory.ErrTransferNotFound)
+	}
+	if status != expected {
+		return "", "", inventory.ErrTransferStatus
+	}
+
+	return fromLocationID, toLocationID, nil
+}
+
+// transferLines reads the lines in variant id order, which is the order their stock levels are locked in
+func transferLines(ctx context.Context, tx *sql.Tx, transferID string) ([]*entity.TransferLine, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT variant_id, quantity_requested, quantity_shipped FROM stock_transfer_lines
+		WHERE transfer_id = $1 ORDER BY variant_id`, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []*entity.TransferLine
+	for rows.Next() {
+		line := new(entity.TransferLine)
+		if err := rows.Scan(&line.VariantID, &line.QuantityRequested, &line.QuantityShipped); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (repo *inventoryRepository) loadTransferLines(ctx context.Context, transfers []*entity.StockTransfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*entity.StockTransfer, len(transfers))
+	ids := make([]string, len(transfers))
+	for i, transfer := range transfers {
+		transfer.Lines = []*entity.TransferLine{}
+		byID[transfer.ID] = transfer
+		ids[i] = transfer.ID
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT tl.transfer_id, tl.variant_id, v.sku, tl.quantity_requested, tl.quantity_shipped,
+			tl.quantity_received, tl.discrepancy_note
+		FROM stock_transfer_lines tl JOIN product_variants v ON v.id = tl.variant_id
+		WHERE tl.transfer_id = ANY($1) ORDER BY v.sku`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var transferID string
+		line := new(entity.TransferLine)
+		err := rows.Scan(
+			&transferID,
+			&line.VariantID,
+			&line.SKU,
+			&line.QuantityRequested,
+			&line.QuantityShipped,
+			&line.QuantityReceived,
+			&line.DiscrepancyNote,
+		)
+		if err != nil {
+			return err
+		}
+
+		if line.QuantityShipped != nil && line.QuantityReceived != nil {
+			line.Discrepancy = *line.QuantityShipped - *line.QuantityReceived
+		}
+		transfer := byID[transferID]
+		transfer.HasDiscrepancy = transfer.HasDiscrepancy || line.Discrepancy != 0
+		transfer.Lines = append(transfer.Lines, line)
+	}
+
+	return rows.Err()
+}
+
+func transferReference(id string) string {
+	return "transfer:" + id
+}
+
+// transferWriteError maps the keys of a transfer and its lines to their domain errors
+func transferWriteError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == pqForeignKeyViolation && pqErr.Constraint == "stock_transfer_lines_variant_id_fkey":
+		return variant.ErrVariantNotFound
+	case pqErr.Code == pqForeignKeyViolation:
+		return location.ErrLocationNotFound
+	case pqErr.Code == pqUniqueViolation:
+		return inventory.ErrDuplicateTransferLine
+	case pqErr.Code == pqCheckViolation:
+		return inventory.ErrSameLocation
+	}
+	return err
+}
+
+func scanTransfer(row rowScanner) (*entity.StockTransfer, error) {
+	transfer := new(entity.StockTransfer)
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.FromLocationID,
+		&transfer.ToLocationID,
+		&transfer.Status,
+		&transfer.Note,
+		&transfer.RequestedBy,
+		&transfer.ShippedBy,
+		&transfer.ReceivedBy,
+		&transfer.CreatedAt,
+		&transfer.ShippedAt,
+		&transfer.ReceivedAt,
+		&transfer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
@@ -0,0 +1,227 @@
+package inventoryUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/inventoryDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/variant"
+	"context"
+	"strings"
+)
+
+type InventoryUC struct {
+	inventoryRepo inventory.InventoryRepository
+	variantRepo   variant.VariantRepository
+	locationRepo  location.LocationRepository
+	config        dto.ConfigData
+}
+
+func NewInventoryUseCase(inventoryRepo inventory.InventoryRepository, variantRepo variant.VariantRepository, locationRepo location.LocationRepository, config dto.ConfigData) inventory.InventoryUseCase {
+	return &InventoryUC{inventoryRepo, variantRepo, locationRepo, config}
+}
+
+func (useCase *InventoryUC) ReceiveStock(ctx context.Context, actorID string, request *inventoryDto.ReceiptRequest) (*entity.StockMovement, error) {
+	return useCase.RecordMovement(ctx, &entity.StockMovement{
+		LocationID: request.LocationID,
+		VariantID:  request.VariantID,
+		Type:       entity.MovementReceive,
+		Quantity:   request.Quantity,
+		Reference:  strings.TrimSpace(request.Reference),
+		Note:       strings.TrimSpace(request.Note),
+		CreatedBy:  &actorID,
+	})
+}
+
+func (useCase *InventoryUC) AdjustStock(ctx context.Context, actorID string, request *inventoryDto.AdjustmentRequest) (*entity.StockMovement, error) {
+	return useCase.RecordMovement(ctx, &entity.StockMovement{
+		LocationID: request.LocationID,
+		VariantID:  request.VariantID,
+		Type:       entity.MovementAdjustment,
+		Quantity:   request.Quantity,
+		ReasonCode: entity.AdjustmentReason(request.ReasonCode),
+		Note:       strings.TrimSpace(request.Note),
+		CreatedBy:  &actorID,
+	})
+}
+
+// RecordMovement is the single entry into the ledger, the sign of the quantity has to match the movement type
+func (useCase *InventoryUC) RecordMovement(ctx context.Context, movement *entity.StockMovement) (*entity.StockMovement, error) {
+	if !validQuantity(movement.Type, movement.Quantity) {
+		return nil, inventory.ErrInvalidMovementAmount
+	}
+
+	id, err := useCase.inventoryRepo.RecordMovement(ctx, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetMovementByID(ctx, id)
+}
+
+func validQuantity(movementType entity.MovementType, quantity int) bool {
+	switch movementType {
+	case entity.MovementReceive, entity.MovementReturn:
+		return quantity > 0
+	case entity.MovementSale:
+		return quantity < 0
+	case entity.MovementAdjustment, entity.MovementTransfer:
+		return quantity != 0
+	}
+	return false
+}
+
+// GetVariantStock reports the stock of one SKU per location together with its totals
+func (useCase *InventoryUC) GetVariantStock(ctx context.Context, variantID string) (*inventoryDto.VariantStockResponse, error) {
+	v, err := useCase.variantRepo.GetVariantByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels, err := useCase.inventoryRepo.GetVariantStockLevels(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := &inventoryDto.VariantStockResponse{VariantID: v.ID, SKU: v.SKU, Locations: levels}
+	for _, level := range levels {
+		stock.OnHand += level.OnHand
+		stock.Reserved += level.Reserved
+		stock.Available += level.Available
+	}
+
+	return stock, nil
+}
+
+func (useCase *InventoryUC) GetStockLevels(ctx context.Context, query *inventoryDto.StockListQuery) ([]*entity.StockLevel, int, error) {
+	if err := useCase.checkLocation(ctx, query.LocationID); err != nil {
+		return nil, 0, err
+	}
+
+	return useCase.inventoryRepo.GetStockLevels(ctx, query)
+}
+
+func (useCase *InventoryUC) GetMovements(ctx context.Context, query *inventoryDto.MovementListQuery) ([]*entity.StockMovement, int, error) {
+	// an unknown variant or location is a 404 rather than an empty history
+	if _, err := useCase.variantRepo.GetVariantByID(ctx, query.VariantID); err != nil {
+		return nil, 0, err
+	}
+	if err := useCase.checkLocation(ctx, query.LocationID); err != nil {
+		return nil, 0, err
+	}
+
+	return useCase.inventoryRepo.GetMovements(ctx, query)
+}
+
+// checkLocation verifies an optional location filter
+func (useCase *InventoryUC) checkLocation(ctx context.Context, locationID string) error {
+	if locationID == "" {
+		return nil
+	}
+	_, err := useCase.locationRepo.GetLocationByID(ctx, locationID)
+	return err
+}
+
+// Reserve holds stock for the configured reservation life time, a new hold under the same reference replaces the
+// previous one. The hold is taken into account and consumed when an allocation completes the checkout.
+func (useCase *InventoryUC) Reserve(ctx context.Context, reference, locationID string, lines []entity.StockLine) ([]*entity.Reservation, error) {
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, inventory.ErrInvalidMovementAmount
+		}
+	}
+
+	err := useCase.inventoryRepo.HoldStock(ctx, reference, locationID, lines, useCase.config.InventoryConfig.ReservationLifeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetReservations(ctx, reference)
+}
+
+func (useCase *InventoryUC) ReleaseReservations(ctx context.Context, reference string) error {
+	return useCase.inventoryRepo.ReleaseReservations(ctx, reference)
+}
+
+func (useCase *InventoryUC) ExpireReservations(ctx context.Context) (int, error) {
+	return useCase.inventoryRepo.ExpireReservations(ctx)
+}
+
+func (useCase *InventoryUC) CreateTransfer(ctx context.Context, actorID string, request *inventoryDto.TransferRequest) (*entity.StockTransfer, error) {
+	if request.FromLocationID == request.ToLocationID {
+		return nil, inventory.ErrSameLocation
+	}
+
+	transfer := &entity.StockTransfer{
+		FromLocationID: request.FromLocationID,
+		ToLocationID:   request.ToLocationID,
+		Note:           strings.TrimSpace(request.Note),
+		RequestedBy:    &actorID,
+	}
+	seen := make(map[string]bool, len(request.Lines))
+	for _, line := range request.Lines {
+		if seen[line.VariantID] {
+			return nil, inventory.ErrDuplicateTransferLine
+		}
+		seen[line.VariantID] = true
+		transfer.Lines = append(transfer.Lines, &entity.TransferLine{VariantID: line.VariantID, QuantityRequested: line.Quantity})
+	}
+
+	id, err := useCase.inventoryRepo.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetTransferByID(ctx, id)
+}
+
+func (useCase *InventoryUC) GetTransferByID(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	return useCase.inventoryRepo.GetTransferByID(ctx, id)
+}
+
+func (useCase *InventoryUC) GetTransfers(ctx context.Context, query *inventoryDto.TransferListQuery) ([]*entity.StockTransfer, int, error) {
+	if err := useCase.checkLocation(ctx, query.LocationID); err != nil {
+		return nil, 0, err
+	}
+
+	return useCase.inventoryRepo.GetTransfers(ctx, query)
+}
+
+func (useCase *InventoryUC) ShipTransfer(ctx context.Context, actorID, id string) (*entity.StockTransfer, error) {
+	if err := useCase.inventoryRepo.ShipTransfer(ctx, id, actorID); err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetTransferByID(ctx, id)
+}
+
+func (useCase *InventoryUC) ReceiveTransfer(ctx context.Context, actorID, id string, request *inventoryDto.ReceiveTransferRequest) (*entity.StockTransfer, error) {
+	received := make([]*entity.TransferLine, 0, len(request.Lines))
+	seen := make(map[string]bool, len(request.Lines))
+	for _, line := range request.Lines {
+		if seen[line.VariantID] {
+			return nil, inventory.ErrDuplicateTransferLine
+		}
+		seen[line.VariantID] = true
+		received = append(received, &entity.TransferLine{
+			VariantID:        line.VariantID,
+			QuantityReceived: line.QuantityReceived,
+			DiscrepancyNote:  strings.TrimSpace(line.DiscrepancyNote),
+		})
+	}
+
+	if err := useCase.inventoryRepo.ReceiveTransfer(ctx, id, actorID, received); err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetTransferByID(ctx, id)
+}
+
+func (useCase *InventoryUC) CancelTransfer(ctx context.Context, id string) (*entity.StockTransfer, error) {
+	if err := useCase.inventoryRepo.CancelTransfer(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return useCase.inventoryRepo.GetTransferByID(ctx, id)
+}
@@ -0,0 +1,129 @@
+package locationDelivery
+
+import (
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/dto/locationDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/location"
+
+	"github.com/gin-gonic/gin"
+)
+
+type locationDelivery struct {
+	locationUC location.LocationUseCase
+}
+
+func NewLocationDelivery(v1Group *gin.RouterGroup, locationUC location.LocationUseCase, jwtAuth gin.HandlerFunc) {
+	handler := locationDelivery{
+		locationUC: locationUC,
+	}
+
+	publicGroup := v1Group.Group("")
+	{
+		publicGroup.GET("/locations", handler.getActiveLocations)
+		publicGroup.GET("/locations/:id", handler.getActiveLocationByID)
+	}
+
+	adminGroup := v1Group.Group("", jwtAuth, middleware.RequirePermission(entity.PermissionInventoryManage))
+	{
+		adminGroup.GET("/locations/manage", handler.getLocations)
+		adminGroup.GET("/locations/manage/:id", handler.getLocationByID)
+		adminGroup.POST("/locations", handler.createLocation)
+		adminGroup.PUT("/locations/:id", handler.updateLocation)
+	}
+}
+
+func (c *locationDelivery) getActiveLocations(ctx *gin.Context) {
+	locationType, validationError := parseLocationType(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "69", "01")
+		return
+	}
+
+	locations, err := c.locationUC.GetActiveLocations(ctx.Request.Context(), locationType)
+	if err != nil {
+		json.AbortWithError(ctx, err, "69")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, locations, "success", "69", "02")
+}
+
+func (c *locationDelivery) getActiveLocationByID(ctx *gin.Context) {
+	l, err := c.locationUC.GetActiveLocationByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "70")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, l, "success", "70", "01")
+}
+
+func (c *locationDelivery) getLocations(ctx *gin.Context) {
+	locationType, validationError := parseLocationType(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "71", "01")
+		return
+	}
+
+	locations, err := c.locationUC.GetLocations(ctx.Request.Context(), locationType)
+	if err != nil {
+		json.AbortWithError(ctx, err, "71")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, locations, "success", "71", "02")
+}
+
+func (c *locationDelivery) getLocationByID(ctx *gin.Context) {
+	l, err := c.locationUC.GetLocationByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "72")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, l, "success", "72", "01")
+}
+
+func (c *locationDelivery) createLocation(ctx *gin.Context) {
+	var locationPayload *locationDto.LocationRequest
+	if validationError := validation.BindJSON(ctx, &locationPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "73", "01")
+		return
+	}
+
+	l, err := c.locationUC.CreateLocation(ctx.Request.Context(), locationPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "73")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, l, "success", "73", "02")
+}
+
+func (c *locationDelivery) updateLocation(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var locationPayload *locationDto.LocationRequest
+	if validationError := validation.BindJSON(ctx, &locationPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "74", "01")
+		return
+	}
+
+	l, err := c.locationUC.UpdateLocation(ctx.Request.Context(), ID, locationPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "74")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, l, "success", "74", "02")
+}
+
+func parseLocationType(ctx *gin.Context) (entity.LocationType, []json.ValidationField) {
+	locationType := entity.LocationType(ctx.Query("type"))
+	if locationType != "" && !locationType.IsValid() {
+		return "", []json.ValidationField{{FieldName: "type", Message: "must be one of store warehouse"}}
+	}
+	return locationType, nil
+}
@@ -0,0 +1,11 @@
+package location
+
+import "clean-architecture/pkg/domainError"
+
+var (
+	ErrLocationNotFound = domainError.NotFound("location not found")
+
+	ErrLocationCodeInUse = domainError.Conflict("location code already in use")
+
+	ErrInvalidOpeningHours = domainError.Validation("bad request", domainError.Field{Name: "opening_hours", Message: "open and close must differ"})
+)
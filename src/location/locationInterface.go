@@ -0,0 +1,24 @@
+package location
+
+import (
+	"clean-architecture/model/dto/locationDto"
+	"clean-architecture/model/entity"
+	"context"
+)
+
+type LocationRepository interface {
+	GetLocations(ctx context.Context, activeOnly bool, locationType entity.LocationType) ([]*entity.Location, error)
+	GetLocationByID(ctx context.Context, id string) (*entity.Location, error)
+	GetLocationByCode(ctx context.Context, code string) (*entity.Location, error)
+	CreateLocation(ctx context.Context, location *entity.Location) (string, error)
+	UpdateLocation(ctx context.Context, location *entity.Location) error
+}
+
+type LocationUseCase interface {
+	GetLocations(ctx context.Context, locationType entity.LocationType) ([]*entity.Location, error)
+	GetActiveLocations(ctx context.Context, locationType entity.LocationType) ([]*entity.Location, error)
+	GetLocationByID(ctx context.Context, id string) (*entity.Location, error)
+	GetActiveLocationByID(ctx context.Context, id string) (*entity.Location, error)
+	CreateLocation(ctx context.Context, location *locationDto.LocationRequest) (*entity.Location, error)
+	UpdateLocation(ctx context.Context, id string, location *locationDto.LocationRequest) (*entity.Location, error)
+}
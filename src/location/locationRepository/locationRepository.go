@@ -0,0 +1,168 @@
+package locationRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/location"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type locationRepository struct {
+	db *sql.DB
+}
+
+func NewLocationRepository(db *sql.DB) location.LocationRepository {
+	return &locationRepository{db}
+}
+
+const locationColumns = `id, code, name, type, address_line, city, province, postal_code, phone, opening_hours, active, created_at, updated_at`
+
+func (repo *locationRepository) GetLocations(ctx context.Context, activeOnly bool, locationType entity.LocationType) ([]*entity.Location, error) {
+	sqlQuery := `SELECT ` + locationColumns + ` FROM locations WHERE ($1 = '' OR type = $1)`
+	if activeOnly {
+		sqlQuery += " AND active"
+	}
+	sqlQuery += " ORDER BY name"
+
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, locationType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []*entity.Location{}
+	for rows.Next() {
+		l, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+
+	return locations, rows.Err()
+}
+
+func (repo *locationRepository) GetLocationByID(ctx context.Context, id string) (*entity.Location, error) {
+	l, err := scanLocation(repo.db.QueryRowContext(ctx, `SELECT `+locationColumns+` FROM locations WHERE id = $1`, id))
+	if err != nil {
+		return nil, notFound(err, location.ErrLocationNotFound)
+	}
+
+	return l, nil
+}
+
+func (repo *locationRepository) GetLocationByCode(ctx context.Context, code string) (*entity.Location, error) {
+	l, err := scanLocation(repo.db.QueryRowContext(ctx, `SELECT `+locationColumns+` FROM locations WHERE code = $1`, code))
+	if err != nil {
+		return nil, notFound(err, location.ErrLocationNotFound)
+	}
+
+	return l, nil
+}
+
+func (repo *locationRepository) CreateLocation(ctx context.Context, l *entity.Location) (string, error) {
+	openingHours, err := json.Marshal(l.OpeningHours)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	err = repo.db.QueryRowContext(ctx, `INSERT INTO locations
+		(code, name, type, address_line, city, province, postal_code, phone, opening_hours, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		l.Code, l.Name, l.Type, l.AddressLine, l.City, l.Province, l.PostalCode, l.Phone, openingHours, l.Active).Scan(&id)
+	if err != nil {
+		return "", locationWriteError(err)
+	}
+
+	return id, nil
+}
+
+func (repo *locationRepository) UpdateLocation(ctx context.Context, l *entity.Location) error {
+	openingHours, err := json.Marshal(l.OpeningHours)
+	if err != nil {
+		return err
+	}
+
+	result, err := repo.db.ExecContext(ctx, `UPDATE locations SET code = $2, name = $3, type = $4, address_line = $5, city = $6,
+		province = $7, postal_code = $8, phone = $9, opening_hours = $10, active = $11, updated_at = NOW()
+		WHERE id = $1`,
+		l.ID, l.Code, l.Name, l.Type, l.AddressLine, l.City, l.Province, l.PostalCode, l.Phone, openingHours, l.Active)
+	if err != nil {
+		return notFound(locationWriteError(err), location.ErrLocationNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return location.ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// locationWriteError maps the unique code index to its domain error
+func locationWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation && pqErr.Constraint == "locations_code_key" {
+		return location.ErrLocationCodeInUse
+	}
+	return err
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLocation(row rowScanner) (*entity.Location, error) {
+	l := new(entity.Location)
+	var openingHours []byte
+	err := row.Scan(
+		&l.ID,
+		&l.Code,
+		&l.Name,
+		&l.Type,
+		&l.AddressLine,
+		&l.City,
+		&l.Province,
+		&l.PostalCode,
+		&l.Phone,
+		&openingHours,
+		&l.Active,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(openingHours, &l.OpeningHours); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
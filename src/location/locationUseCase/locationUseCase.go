@@ -0,0 +1,100 @@
+package locationUseCase
+
+import (
+	"clean-architecture/model/dto/locationDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/location"
+	"context"
+	"strings"
+)
+
+type LocationUC struct {
+	locationRepo location.LocationRepository
+}
+
+func NewLocationUseCase(locationRepo location.LocationRepository) location.LocationUseCase {
+	return &LocationUC{locationRepo}
+}
+
+// GetLocations lists every location, inactive ones included, optionally of one type
+func (useCase *LocationUC) GetLocations(ctx context.Context, locationType entity.LocationType) ([]*entity.Location, error) {
+	return useCase.locationRepo.GetLocations(ctx, false, locationType)
+}
+
+// GetActiveLocations is the store finder view
+func (useCase *LocationUC) GetActiveLocations(ctx context.Context, locationType entity.LocationType) ([]*entity.Location, error) {
+	return useCase.locationRepo.GetLocations(ctx, true, locationType)
+}
+
+func (useCase *LocationUC) GetLocationByID(ctx context.Context, id string) (*entity.Location, error) {
+	return useCase.locationRepo.GetLocationByID(ctx, id)
+}
+
+func (useCase *LocationUC) GetActiveLocationByID(ctx context.Context, id string) (*entity.Location, error) {
+	l, err := useCase.locationRepo.GetLocationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !l.Active {
+		return nil, location.ErrLocationNotFound
+	}
+
+	return l, nil
+}
+
+func (useCase *LocationUC) CreateLocation(ctx context.Context, request *locationDto.LocationRequest) (*entity.Location, error) {
+	newLocation, err := toLocation(request)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := useCase.locationRepo.CreateLocation(ctx, newLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.locationRepo.GetLocationByID(ctx, id)
+}
+
+func (useCase *LocationUC) UpdateLocation(ctx context.Context, id string, request *locationDto.LocationRequest) (*entity.Location, error) {
+	updated, err := toLocation(request)
+	if err != nil {
+		return nil, err
+	}
+	updated.ID = id
+
+	if err := useCase.locationRepo.UpdateLocation(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	return useCase.locationRepo.GetLocationByID(ctx, id)
+}
+
+// toLocation normalizes a request, a location is active unless the request says otherwise
+func toLocation(request *locationDto.LocationRequest) (*entity.Location, error) {
+	openingHours := make([]entity.OpeningHours, len(request.OpeningHours))
+	for i, hours := range request.OpeningHours {
+		if hours.Open == hours.Close {
+			return nil, location.ErrInvalidOpeningHours
+		}
+		openingHours[i] = entity.OpeningHours{Day: hours.Day, Open: hours.Open, Close: hours.Close}
+	}
+
+	l := &entity.Location{
+		Code:         strings.ToUpper(strings.TrimSpace(request.Code)),
+		Name:         strings.TrimSpace(request.Name),
+		Type:         entity.LocationType(request.Type),
+		AddressLine:  strings.TrimSpace(request.AddressLine),
+		City:         strings.TrimSpace(request.City),
+		Province:     strings.TrimSpace(request.Province),
+		PostalCode:   strings.TrimSpace(request.PostalCode),
+		Phone:        strings.TrimSpace(request.Phone),
+		OpeningHours: openingHours,
+		Active:       true,
+	}
+	if request.Active != nil {
+		l.Active = *request.Active
+	}
+
+	return l, nil
+}
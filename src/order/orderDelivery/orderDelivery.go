@@ -0,0 +1,115 @@
+package orderDelivery
+
+import (
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/order"
+	"clean-architecture/utils"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type orderDelivery struct {
+	orderUC order.OrderUseCase
+}
+
+// NewOrderDelivery lets only age verified accounts place orders, ageVerified must run after jwtAuth
+func NewOrderDelivery(v1Group *gin.RouterGroup, orderUC order.OrderUseCase, jwtAuth, ageVerified gin.HandlerFunc) {
+	handler := orderDelivery{
+		orderUC: orderUC,
+	}
+
+	orderGroup := v1Group.Group("/orders", jwtAuth)
+	{
+		orderGroup.POST("/checkout", ageVerified, handler.checkout)
+		orderGroup.POST("", ageVerified, handler.placeOrder)
+		orderGroup.GET("", handler.getOrders)
+		orderGroup.GET("/:id", handler.getOrderByID)
+	}
+}
+
+func (c *orderDelivery) checkout(ctx *gin.Context) {
+	var checkoutPayload *orderDto.CreateOrderRequest
+	if validationError := validation.BindJSON(ctx, &checkoutPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "91", "01")
+		return
+	}
+
+	checkout, err := c.orderUC.Checkout(ctx.Request.Context(), middleware.GetActor(ctx).ID, checkoutPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "91")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, checkout, "success", "91", "02")
+}
+
+func (c *orderDelivery) placeOrder(ctx *gin.Context) {
+	var orderPayload *orderDto.CreateOrderRequest
+	if validationError := validation.BindJSON(ctx, &orderPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "88", "01")
+		return
+	}
+
+	placed, err := c.orderUC.PlaceOrder(ctx.Request.Context(), middleware.GetActor(ctx).ID, ctx.GetHeader(orderDto.IdempotencyKeyHeader), orderPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "88")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, placed, "success", "88", "02")
+}
+
+func (c *orderDelivery) getOrders(ctx *gin.Context) {
+	page, size, validationError := parsePageQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "89", "01")
+		return
+	}
+
+	query := &orderDto.OrderListQuery{UserID: middleware.GetActor(ctx).ID, Page: page, Size: size}
+	orders, total, err := c.orderUC.GetOrders(ctx.Request.Context(), query)
+	if err != nil {
+		json.AbortWithError(ctx, err, "89")
+		return
+	}
+
+	json.NewResponseSuccessPage(ctx, orders, json.NewPaging(page, size, total, ""), "success", "89", "02")
+}
+
+func (c *orderDelivery) getOrderByID(ctx *gin.Context) {
+	o, err := c.orderUC.GetOrder(ctx.Request.Context(), middleware.GetActor(ctx), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "90")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, o, "success", "90", "01")
+}
+
+// parsePageQuery reads page and size, defaulting to the first page of DefaultPageSize
+func parsePageQuery(ctx *gin.Context) (int, int, []json.ValidationField) {
+	var validationError []json.ValidationField
+	page, size := 1, orderDto.DefaultPageSize
+
+	if value := ctx.Query("page"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 {
+			validationError = append(validationError, json.ValidationField{FieldName: "page", Message: "must be a positive number"})
+		}
+		page = parsed
+	}
+
+	if value := ctx.Query("size"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 || parsed > orderDto.MaxPageSize {
+			validationError = append(validationError, json.ValidationField{FieldName: "size", Message: fmt.Sprintf("must be between 1 and %d", orderDto.MaxPageSize)})
+		}
+		size = parsed
+	}
+
+	return page, size, validationError
+}
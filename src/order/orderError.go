@@ -0,0 +1,32 @@
+package order
+
+import (
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/pkg/domainError"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrOrderNotFound = domainError.NotFound("order not found")
+
+	ErrIdempotencyKeyReused = domainError.Conflict("idempotency key was already used for a different order")
+	ErrPricesChanged        = domainError.Conflict("prices in the cart have changed, review the cart before ordering")
+
+	ErrIdempotencyKeyRequired  = domainError.Validation("bad request", domainError.Field{Name: orderDto.IdempotencyKeyHeader, Message: fmt.Sprintf("required, at most %d characters", orderDto.MaxIdempotencyKeyLength)})
+	ErrEmptyOrder              = domainError.Validation("bad request", domainError.Field{Name: "items", Message: "the order has no items and the cart is empty"})
+	ErrDuplicateItem           = domainError.Validation("bad request", domainError.Field{Name: "items", Message: "each variant may appear only once"})
+	ErrPickupLocationRequired  = domainError.Validation("bad request", domainError.Field{Name: "pickup_location_id", Message: "required for pickup"})
+	ErrInvalidPickupLocation   = domainError.Validation("bad request", domainError.Field{Name: "pickup_location_id", Message: "must be an open store"})
+	ErrShippingAddressRequired = domainError.Validation("bad request", domainError.Field{Name: "shipping_address", Message: "required for delivery"})
+)
+
+// UnavailableItems is the conflict for variants that can no longer be bought
+func UnavailableItems(skus []string) error {
+	return domainError.Conflict("no longer available: " + strings.Join(skus, ", "))
+}
+
+// InsufficientStock is the conflict for variants without enough stock at the fulfilling location
+func InsufficientStock(skus []string) error {
+	return domainError.Conflict("insufficient stock for " + strings.Join(skus, ", "))
+}
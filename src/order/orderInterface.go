@@ -0,0 +1,21 @@
+package order
+
+import (
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/model/entity"
+	"context"
+)
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *entity.Order, cartID, holdReference string) (string, error)
+	GetOrderByID(ctx context.Context, id string) (*entity.Order, error)
+	GetOrderByIdempotencyKey(ctx context.Context, userID, idempotencyKey string) (*entity.Order, error)
+	GetOrders(ctx context.Context, query *orderDto.OrderListQuery) ([]*entity.Order, int, error)
+}
+
+type OrderUseCase interface {
+	Checkout(ctx context.Context, userID string, request *orderDto.CreateOrderRequest) (*orderDto.CheckoutResponse, error)
+	PlaceOrder(ctx context.Context, userID, idempotencyKey string, request *orderDto.CreateOrderRequest) (*entity.Order, error)
+	GetOrder(ctx context.Context, actor entity.Actor, id string) (*entity.Order, error)
+	GetOrders(ctx context.Context, query *orderDto.OrderListQuery) ([]*entity.Order, int, error)
+}
@@ -0,0 +1,283 @@
+package orderRepository
+
+import (
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/order"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type orderRepository struct {
+	db            *sql.DB
+	inventoryRepo inventory.InventoryRepository
+}
+
+// NewOrderRepository takes the stock of an order through the inventory ledger, inside the transaction of the order
+func NewOrderRepository(db *sql.DB, inventoryRepo inventory.InventoryRepository) order.OrderRepository {
+	return &orderRepository{db, inventoryRepo}
+}
+
+const orderColumns = `id, user_id, status, fulfilment_method, location_id, shipping_address, subtotal, discount, shipping_fee, tax, total,
+	idempotency_key, request_hash, created_at, updated_at`
+
+// CreateOrder places an order in one transaction: the order and its items are written, the stock of every item
+// is taken out of the fulfilling location, consuming what the checkout under holdReference held, and when
+// cartID is set the ordered lines leave the cart.
+func (repo *orderRepository) CreateOrder(ctx context.Context, o *entity.Order, cartID, holdReference string) (string, error) {
+	encodedAddress, err := json.Marshal(o.ShippingAddress)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// the idempotency key is claimed first, a concurrent retry blocks here until this transaction ends
+	var id string
+	err = tx.QueryRowContext(ctx, `INSERT INTO orders (user_id, status, fulfilment_method, location_id, shipping_address,
+			subtotal, discount, shipping_fee, tax, total, idempotency_key, request_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		o.UserID, o.Status, o.FulfilmentMethod, o.LocationID, encodedAddress,
+		o.Subtotal, o.Discount, o.ShippingFee, o.Tax, o.Total, o.IdempotencyKey, o.RequestHash).Scan(&id)
+	if err != nil {
+		return "", orderWriteError(err)
+	}
+
+	allocation := &entity.StockAllocation{
+		LocationID:    o.LocationID,
+		Reference:     orderReference(id),
+		HoldReference: holdReference,
+		CreatedBy:     &o.UserID,
+	}
+	variantIDs := make([]string, len(o.Items))
+	for i, item := range o.Items {
+		allocation.Lines = append(allocation.Lines, entity.StockLine{VariantID: item.VariantID, Quantity: item.Quantity})
+		variantIDs[i] = item.VariantID
+	}
+	if err := repo.inventoryRepo.AllocateStock(ctx, tx, allocation); err != nil {
+		return "", err
+	}
+
+	for _, item := range o.Items {
+		encodedOptions, err := json.Marshal(item.Options)
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO order_items (order_id, variant_id, product_id, product_name, sku, options, quantity, unit_price, line_total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			id, item.VariantID, item.ProductID, item.ProductName, item.SKU, encodedOptions, item.Quantity, item.UnitPrice, item.LineTotal)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if cartID != "" {
+		_, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id = $1 AND variant_id = ANY($2)`, cartID, pq.Array(variantIDs))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *orderRepository) GetOrderByID(ctx context.Context, id string) (*entity.Order, error) {
+	o, err := scanOrder(repo.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1`, id))
+	if err != nil {
+		return nil, notFound(err, order.ErrOrderNotFound)
+	}
+
+	if err := repo.loadOrderItems(ctx, []*entity.Order{o}); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (repo *orderRepository) GetOrderByIdempotencyKey(ctx context.Context, userID, idempotencyKey string) (*entity.Order, error) {
+	o, err := scanOrder(repo.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE user_id = $1 AND idempotency_key = $2`,
+		userID, idempotencyKey))
+	if err != nil {
+		return nil, notFound(err, order.ErrOrderNotFound)
+	}
+
+	if err := repo.loadOrderItems(ctx, []*entity.Order{o}); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// GetOrders returns one page of the orders of a user, newest first
+func (repo *orderRepository) GetOrders(ctx context.Context, query *orderDto.OrderListQuery) ([]*entity.Order, int, error) {
+	var total int
+	err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders WHERE user_id = $1`, query.UserID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`, query.UserID, query.Size, (query.Page-1)*query.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	orders := []*entity.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := repo.loadOrderItems(ctx, orders); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+func (repo *orderRepository) loadOrderItems(ctx context.Context, orders []*entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*entity.Order, len(orders))
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		o.Items = []*entity.OrderItem{}
+		byID[o.ID] = o
+		ids[i] = o.ID
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT order_id, variant_id, product_id, product_name, sku, options, quantity, unit_price, line_total
+		FROM order_items WHERE order_id = ANY($1) ORDER BY product_name, sku`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var encodedOptions []byte
+		item := new(entity.OrderItem)
+		err := rows.Scan(
+			&orderID,
+			&item.VariantID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.SKU,
+			&encodedOptions,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.LineTotal,
+		)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(encodedOptions, &item.Options); err != nil {
+			return err
+		}
+
+		o := byID[orderID]
+		o.Items = append(o.Items, item)
+	}
+
+	return rows.Err()
+}
+
+func orderReference(id string) string {
+	return "order:" + id
+}
+
+// orderWriteError maps the keys of an order to their domain errors
+func orderWriteError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == pqUniqueViolation && pqErr.Constraint == "orders_idempotency_key":
+		return order.ErrIdempotencyKeyReused
+	case pqErr.Code == pqForeignKeyViolation && pqErr.Constraint == "orders_location_id_fkey":
+		return location.ErrLocationNotFound
+	}
+	return err
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqForeignKeyViolation       = "23503"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row rowScanner) (*entity.Order, error) {
+	o := new(entity.Order)
+	var encodedAddress []byte
+	err := row.Scan(
+		&o.ID,
+		&o.UserID,
+		&o.Status,
+		&o.FulfilmentMethod,
+		&o.LocationID,
+		&encodedAddress,
+		&o.Subtotal,
+		&o.Discount,
+		&o.ShippingFee,
+		&o.Tax,
+		&o.Total,
+		&o.IdempotencyKey,
+		&o.RequestHash,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// a pickup order stores a JSON null, which leaves the address nil
+	if len(encodedAddress) > 0 {
+		if err := json.Unmarshal(encodedAddress, &o.ShippingAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
@@ -0,0 +1,392 @@
+package orderUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/cart"
+	"clean-architecture/src/inventory"
+	"clean-architecture/src/location"
+	"clean-architecture/src/order"
+	"clean-architecture/src/product"
+	"clean-architecture/src/variant"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+type OrderUC struct {
+	orderRepo    order.OrderRepository
+	cartRepo     cart.CartRepository
+	variantRepo  variant.VariantRepository
+	productRepo  product.ProductRepository
+	locationRepo location.LocationRepository
+	inventoryUC  inventory.InventoryUseCase
+	config       dto.ConfigData
+}
+
+func NewOrderUseCase(orderRepo order.OrderRepository, cartRepo cart.CartRepository, variantRepo variant.VariantRepository,
+	productRepo product.ProductRepository, locationRepo location.LocationRepository, inventoryUC inventory.InventoryUseCase,
+	config dto.ConfigData) order.OrderUseCase {
+	return &OrderUC{orderRepo, cartRepo, variantRepo, productRepo, locationRepo, inventoryUC, config}
+}
+
+// Checkout prices what PlaceOrder would order for the same request and holds its stock for the reservation
+// life time, so the items cannot sell out while the customer pays. A new checkout replaces the previous hold.
+func (useCase *OrderUC) Checkout(ctx context.Context, userID string, request *orderDto.CreateOrderRequest) (*orderDto.CheckoutResponse, error) {
+	o, _, err := useCase.quote(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]entity.StockLine, len(o.Items))
+	for i, item := range o.Items {
+		lines[i] = entity.StockLine{VariantID: item.VariantID, Quantity: item.Quantity}
+	}
+	reservations, err := useCase.inventoryUC.Reserve(ctx, checkoutReference(userID), o.LocationID, lines)
+	if err != nil {
+		return nil, shortageError(err, o.Items)
+	}
+
+	checkout := &orderDto.CheckoutResponse{
+		FulfilmentMethod: o.FulfilmentMethod,
+		LocationID:       o.LocationID,
+		Items:            o.Items,
+		Subtotal:         o.Subtotal,
+		Discount:         o.Discount,
+		ShippingFee:      o.ShippingFee,
+		Tax:              o.Tax,
+		Total:            o.Total,
+	}
+	for _, reservation := range reservations {
+		if checkout.HeldUntil.IsZero() || reservation.ExpiresAt.Before(checkout.HeldUntil) {
+			checkout.HeldUntil = reservation.ExpiresAt
+		}
+	}
+
+	return checkout, nil
+}
+
+// PlaceOrder checks out the items of the request, or the cart of the user when the request has none.
+// A request repeating the idempotency key of an earlier one gets that order back instead of a second order,
+// as long as it asks for the same thing.
+func (useCase *OrderUC) PlaceOrder(ctx context.Context, userID, idempotencyKey string, request *orderDto.CreateOrderRequest) (*entity.Order, error) {
+	if idempotencyKey == "" || len(idempotencyKey) > orderDto.MaxIdempotencyKeyLength {
+		return nil, order.ErrIdempotencyKeyRequired
+	}
+
+	requestHash, err := hashRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// a retry after the first attempt went through must not rebuild the order, the cart it came from is empty by now
+	existing, err := useCase.orderRepo.GetOrderByIdempotencyKey(ctx, userID, idempotencyKey)
+	if err == nil {
+		return replay(existing, requestHash)
+	}
+	if !errors.Is(err, order.ErrOrderNotFound) {
+		return nil, err
+	}
+
+	placed, err := useCase.createOrder(ctx, userID, idempotencyKey, requestHash, request)
+	if err != nil {
+		// the stock held by the checkout goes back as soon as the order fails, a successful order has consumed it
+		if releaseErr := useCase.inventoryUC.ReleaseReservations(ctx, checkoutReference(userID)); releaseErr != nil {
+			log.Warn().Msg("PlaceOrder.ReleaseReservations.err : " + releaseErr.Error())
+		}
+		return nil, err
+	}
+
+	return placed, nil
+}
+
+func (useCase *OrderUC) createOrder(ctx context.Context, userID, idempotencyKey, requestHash string, request *orderDto.CreateOrderRequest) (*entity.Order, error) {
+	o, cartID, err := useCase.quote(ctx, userID, request)
+	if err != nil {
+		return nil, err
+	}
+	o.IdempotencyKey = idempotencyKey
+	o.RequestHash = requestHash
+
+	id, err := useCase.orderRepo.CreateOrder(ctx, o, cartID, checkoutReference(userID))
+	if errors.Is(err, order.ErrIdempotencyKeyReused) {
+		// a concurrent request with the same key won the race, its order is committed by now
+		existing, err := useCase.orderRepo.GetOrderByIdempotencyKey(ctx, userID, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+		return replay(existing, requestHash)
+	}
+	if err != nil {
+		return nil, shortageError(err, o.Items)
+	}
+
+	return useCase.orderRepo.GetOrderByID(ctx, id)
+}
+
+// quote builds and prices the order a request asks for, cartID is set when the items come from the cart of the user
+func (useCase *OrderUC) quote(ctx context.Context, userID string, request *orderDto.CreateOrderRequest) (*entity.Order, string, error) {
+	o := &entity.Order{
+		UserID:           userID,
+		Status:           entity.OrderStatusPlaced,
+		FulfilmentMethod: entity.FulfilmentMethod(request.FulfilmentMethod),
+	}
+
+	fulfilledFrom, err := useCase.fulfilmentLocation(ctx, request)
+	if err != nil {
+		return nil, "", err
+	}
+	o.LocationID = fulfilledFrom.ID
+	if o.FulfilmentMethod == entity.FulfilmentDelivery {
+		o.ShippingAddress = shippingAddress(request.ShippingAddress)
+	}
+
+	var cartID string
+	if len(request.Items) > 0 {
+		o.Items, err = useCase.requestedItems(ctx, request.Items)
+	} else {
+		cartID, o.Items, err = useCase.cartItems(ctx, userID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	price(o, useCase.config.OrderConfig)
+	return o, cartID, nil
+}
+
+// checkoutReference is the reference of the stock a checkout holds, a user has at most one checkout at a time
+func checkoutReference(userID string) string {
+	return "checkout:" + userID
+}
+
+// shortageError names the SKUs of the variants the inventory came up short on
+func shortageError(err error, items []*entity.OrderItem) error {
+	var shortage *inventory.InsufficientStockError
+	if !errors.As(err, &shortage) {
+		return err
+	}
+
+	short := make(map[string]bool, len(shortage.VariantIDs))
+	for _, variantID := range shortage.VariantIDs {
+		short[variantID] = true
+	}
+	var skus []string
+	for _, item := range items {
+		if short[item.VariantID] {
+			skus = append(skus, item.SKU)
+		}
+	}
+	sort.Strings(skus)
+
+	return order.InsufficientStock(skus)
+}
+
+// GetOrder hides orders of other users unless the actor may read every order
+func (useCase *OrderUC) GetOrder(ctx context.Context, actor entity.Actor, id string) (*entity.Order, error) {
+	o, err := useCase.orderRepo.GetOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanManage(o.UserID, entity.PermissionOrderRead) {
+		return nil, order.ErrOrderNotFound
+	}
+
+	return o, nil
+}
+
+func (useCase *OrderUC) GetOrders(ctx context.Context, query *orderDto.OrderListQuery) ([]*entity.Order, int, error) {
+	return useCase.orderRepo.GetOrders(ctx, query)
+}
+
+// replay returns the order an idempotency key was first used for, provided the request is the same one
+func replay(existing *entity.Order, requestHash string) (*entity.Order, error) {
+	if existing.RequestHash != requestHash {
+		return nil, order.ErrIdempotencyKeyReused
+	}
+	return existing, nil
+}
+
+// hashRequest fingerprints a request independent of the order its items were listed in
+func hashRequest(request *orderDto.CreateOrderRequest) (string, error) {
+	normalized := *request
+	normalized.Items = make([]orderDto.OrderItemRequest, len(request.Items))
+	copy(normalized.Items, request.Items)
+	sort.Slice(normalized.Items, func(i, j int) bool { return normalized.Items[i].VariantID < normalized.Items[j].VariantID })
+
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fulfilmentLocation is the store a pickup order is collected from, or the online location for a delivery
+func (useCase *OrderUC) fulfilmentLocation(ctx context.Context, request *orderDto.CreateOrderRequest) (*entity.Location, error) {
+	if entity.FulfilmentMethod(request.FulfilmentMethod) == entity.FulfilmentPickup {
+		if request.PickupLocationID == "" {
+			return nil, order.ErrPickupLocationRequired
+		}
+
+		l, err := useCase.locationRepo.GetLocationByID(ctx, request.PickupLocationID)
+		if errors.Is(err, location.ErrLocationNotFound) {
+			return nil, order.ErrInvalidPickupLocation
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !l.Active || l.Type != entity.LocationTypeStore {
+			return nil, order.ErrInvalidPickupLocation
+		}
+		return l, nil
+	}
+
+	if request.ShippingAddress == nil {
+		return nil, order.ErrShippingAddressRequired
+	}
+
+	code := useCase.config.InventoryConfig.OnlineLocationCode
+	l, err := useCase.locationRepo.GetLocationByCode(ctx, code)
+	if errors.Is(err, location.ErrLocationNotFound) {
+		return nil, errors.New("online location " + code + " does not exist")
+	}
+
+	return l, err
+}
+
+func shippingAddress(request *orderDto.ShippingAddressRequest) *entity.ShippingAddress {
+	return &entity.ShippingAddress{
+		RecipientName: request.RecipientName,
+		Phone:         request.Phone,
+		AddressLine:   request.AddressLine,
+		City:          request.City,
+		Province:      request.Province,
+		PostalCode:    request.PostalCode,
+		Note:          request.Note,
+	}
+}
+
+// requestedItems snapshots the variants of a line item payload at their current price
+func (useCase *OrderUC) requestedItems(ctx context.Context, requested []orderDto.OrderItemRequest) ([]*entity.OrderItem, error) {
+	items := make([]*entity.OrderItem, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	products := make(map[string]*entity.Product)
+	var unavailable []string
+
+	for _, line := range requested {
+		if seen[line.VariantID] {
+			return nil, order.ErrDuplicateItem
+		}
+		seen[line.VariantID] = true
+
+		v, err := useCase.variantRepo.GetVariantByID(ctx, line.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		p, ok := products[v.ProductID]
+		if !ok {
+			p, err = useCase.productRepo.GetProductByID(ctx, v.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			products[p.ID] = p
+		}
+
+		if !v.Active || p.Status != entity.ProductStatusActive {
+			unavailable = append(unavailable, v.SKU)
+			continue
+		}
+		items = append(items, &entity.OrderItem{
+			VariantID:   v.ID,
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			SKU:         v.SKU,
+			Options:     v.Options,
+			Quantity:    line.Quantity,
+			UnitPrice:   v.Price,
+		})
+	}
+
+	if len(unavailable) > 0 {
+		return nil, order.UnavailableItems(unavailable)
+	}
+
+	return items, nil
+}
+
+// cartItems snapshots the cart of the user. The customer pays the prices the cart last showed them,
+// so a price that changed since then stops the order until the cart has been looked at again.
+func (useCase *OrderUC) cartItems(ctx context.Context, userID string) (string, []*entity.OrderItem, error) {
+	cartID, err := useCase.cartRepo.EnsureUserCart(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cartItems, err := useCase.cartRepo.GetCartItems(ctx, cartID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(cartItems) == 0 {
+		return "", nil, order.ErrEmptyOrder
+	}
+
+	items := make([]*entity.OrderItem, 0, len(cartItems))
+	var unavailable []string
+	pricesChanged := false
+	for _, item := range cartItems {
+		if !item.Purchasable {
+			unavailable = append(unavailable, item.SKU)
+			continue
+		}
+		pricesChanged = pricesChanged || item.UnitPrice != item.CurrentPrice
+
+		items = append(items, &entity.OrderItem{
+			VariantID:   item.VariantID,
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Options:     item.Options,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.CurrentPrice,
+		})
+	}
+
+	if len(unavailable) > 0 {
+		return "", nil, order.UnavailableItems(unavailable)
+	}
+	if pricesChanged {
+		return "", nil, order.ErrPricesChanged
+	}
+
+	return cartID, items, nil
+}
+
+// price fills in the line totals and the amounts of the order, all in whole rupiah.
+// There are no promotions yet, so the discount is always zero. Shipping is free for pickups and from the
+// free shipping threshold on, tax is charged on the discounted subtotal and rounded half up to the rupiah.
+func price(o *entity.Order, config dto.OrderConfig) {
+	o.Subtotal = 0
+	for _, item := range o.Items {
+		item.LineTotal = item.UnitPrice * int64(item.Quantity)
+		o.Subtotal += item.LineTotal
+	}
+
+	o.Discount = 0
+	taxable := o.Subtotal - o.Discount
+
+	o.ShippingFee = config.ShippingFee
+	if o.FulfilmentMethod == entity.FulfilmentPickup || (config.FreeShippingThreshold > 0 && taxable >= config.FreeShippingThreshold) {
+		o.ShippingFee = 0
+	}
+
+	o.Tax = (taxable*int64(config.TaxRate) + 5000) / 10000
+	o.Total = taxable + o.ShippingFee + o.Tax
+}
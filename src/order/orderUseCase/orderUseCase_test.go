@@ -0,0 +1,214 @@
+package orderUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/orderDto"
+	"clean-architecture/model/entity"
+	"testing"
+)
+
+func TestPrice(t *testing.T) {
+	config := dto.OrderConfig{ShippingFee: 20000, FreeShippingThreshold: 500000, TaxRate: 1100}
+
+	tests := []struct {
+		name             string
+		config           dto.OrderConfig
+		fulfilmentMethod entity.FulfilmentMethod
+		items            []*entity.OrderItem
+		wantSubtotal     int64
+		wantShippingFee  int64
+		wantTax          int64
+		wantTotal        int64
+	}{
+		{
+			name:             "delivery below the threshold pays shipping",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentDelivery,
+			items:            []*entity.OrderItem{{Quantity: 2, UnitPrice: 100000}, {Quantity: 1, UnitPrice: 50000}},
+			wantSubtotal:     250000,
+			wantShippingFee:  20000,
+			wantTax:          27500,
+			wantTotal:        297500,
+		},
+		{
+			name:             "delivery at the threshold ships free",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentDelivery,
+			items:            []*entity.OrderItem{{Quantity: 5, UnitPrice: 100000}},
+			wantSubtotal:     500000,
+			wantShippingFee:  0,
+			wantTax:          55000,
+			wantTotal:        555000,
+		},
+		{
+			name:             "delivery just below the threshold pays shipping",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentDelivery,
+			items:            []*entity.OrderItem{{Quantity: 1, UnitPrice: 499999}},
+			wantSubtotal:     499999,
+			wantShippingFee:  20000,
+			wantTax:          55000,
+			wantTotal:        574999,
+		},
+		{
+			name:             "a zero threshold never waives shipping",
+			config:           dto.OrderConfig{ShippingFee: 20000, TaxRate: 1100},
+			fulfilmentMethod: entity.FulfilmentDelivery,
+			items:            []*entity.OrderItem{{Quantity: 10, UnitPrice: 1000000}},
+			wantSubtotal:     10000000,
+			wantShippingFee:  20000,
+			wantTax:          1100000,
+			wantTotal:        11120000,
+		},
+		{
+			name:             "pickup never pays shipping",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentPickup,
+			items:            []*entity.OrderItem{{Quantity: 1, UnitPrice: 10000}},
+			wantSubtotal:     10000,
+			wantShippingFee:  0,
+			wantTax:          1100,
+			wantTotal:        11100,
+		},
+		{
+			name:             "tax below half a rupiah rounds down",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentPickup,
+			items:            []*entity.OrderItem{{Quantity: 1, UnitPrice: 1004}},
+			wantSubtotal:     1004,
+			wantTax:          110,
+			wantTotal:        1114,
+		},
+		{
+			name:             "tax above half a rupiah rounds up",
+			config:           config,
+			fulfilmentMethod: entity.FulfilmentPickup,
+			items:            []*entity.OrderItem{{Quantity: 1, UnitPrice: 1005}},
+			wantSubtotal:     1005,
+			wantTax:          111,
+			wantTotal:        1116,
+		},
+		{
+			name:             "tax of exactly half a rupiah rounds up",
+			config:           dto.OrderConfig{TaxRate: 1000},
+			fulfilmentMethod: entity.FulfilmentPickup,
+			items:            []*entity.OrderItem{{Quantity: 1, UnitPrice: 5}},
+			wantSubtotal:     5,
+			wantTax:          1,
+			wantTotal:        6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &entity.Order{FulfilmentMethod: tt.fulfilmentMethod, Items: tt.items}
+			price(o, tt.config)
+
+			if o.Subtotal != tt.wantSubtotal {
+				t.Errorf("Subtotal = %d, want %d", o.Subtotal, tt.wantSubtotal)
+			}
+			if o.ShippingFee != tt.wantShippingFee {
+				t.Errorf("ShippingFee = %d, want %d", o.ShippingFee, tt.wantShippingFee)
+			}
+			if o.Tax != tt.wantTax {
+				t.Errorf("Tax = %d, want %d", o.Tax, tt.wantTax)
+			}
+			if o.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", o.Total, tt.wantTotal)
+			}
+			for _, item := range o.Items {
+				if item.LineTotal != item.UnitPrice*int64(item.Quantity) {
+					t.Errorf("LineTotal = %d, want %d", item.LineTotal, item.UnitPrice*int64(item.Quantity))
+				}
+			}
+		})
+	}
+}
+
+func TestHashRequest(t *testing.T) {
+	base := &orderDto.CreateOrderRequest{
+		FulfilmentMethod: "delivery",
+		Items: []orderDto.OrderItemRequest{
+			{VariantID: "a", Quantity: 1},
+			{VariantID: "b", Quantity: 2},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		request  *orderDto.CreateOrderRequest
+		wantSame bool
+	}{
+		{
+			name:     "same request",
+			request:  base,
+			wantSame: true,
+		},
+		{
+			name: "items in another order",
+			request: &orderDto.CreateOrderRequest{
+				FulfilmentMethod: "delivery",
+				Items: []orderDto.OrderItemRequest{
+					{VariantID: "b", Quantity: 2},
+					{VariantID: "a", Quantity: 1},
+				},
+			},
+			wantSame: true,
+		},
+		{
+			name: "another quantity",
+			request: &orderDto.CreateOrderRequest{
+				FulfilmentMethod: "delivery",
+				Items: []orderDto.OrderItemRequest{
+					{VariantID: "a", Quantity: 1},
+					{VariantID: "b", Quantity: 3},
+				},
+			},
+			wantSame: false,
+		},
+		{
+			name: "another fulfilment method",
+			request: &orderDto.CreateOrderRequest{
+				FulfilmentMethod: "pickup",
+				Items: []orderDto.OrderItemRequest{
+					{VariantID: "a", Quantity: 1},
+					{VariantID: "b", Quantity: 2},
+				},
+			},
+			wantSame: false,
+		},
+	}
+
+	want, err := hashRequest(base)
+	if err != nil {
+		t.Fatalf("hashRequest() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hashRequest(tt.request)
+			if err != nil {
+				t.Fatalf("hashRequest() error = %v", err)
+			}
+			if (got == want) != tt.wantSame {
+				t.Errorf("hashRequest() same = %v, want %v", got == want, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestHashRequestKeepsItemOrder(t *testing.T) {
+	request := &orderDto.CreateOrderRequest{
+		Items: []orderDto.OrderItemRequest{
+			{VariantID: "b", Quantity: 2},
+			{VariantID: "a", Quantity: 1},
+		},
+	}
+
+	if _, err := hashRequest(request); err != nil {
+		t.Fatalf("hashRequest() error = %v", err)
+	}
+	if request.Items[0].VariantID != "b" {
+		t.Errorf("hashRequest() reordered the items of the request")
+	}
+}
@@ -0,0 +1,292 @@
+package productDelivery
+
+import (
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/dto/productDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/product"
+	"clean-architecture/utils"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type productDelivery struct {
+	productUC product.ProductUseCase
+}
+
+func NewProductDelivery(v1Group *gin.RouterGroup, productUC product.ProductUseCase, jwtAuth gin.HandlerFunc) {
+	handler := productDelivery{
+		productUC: productUC,
+	}
+
+	// the storefront reads the catalog without logging in and only ever sees active products
+	publicGroup := v1Group.Group("")
+	{
+		publicGroup.GET("/brands", handler.getBrands)
+		publicGroup.GET("/brands/:slug", handler.getBrandBySlug)
+		publicGroup.GET("/categories", handler.getCategoryTree)
+		publicGroup.GET("/categories/:slug", handler.getCategoryBySlug)
+		publicGroup.GET("/products", handler.getActiveProducts)
+		publicGroup.GET("/products/:slug", handler.getActiveProductBySlug)
+	}
+
+	adminGroup := v1Group.Group("", jwtAuth, middleware.RequirePermission(entity.PermissionCatalogManage))
+	{
+		adminGroup.POST("/brands", handler.createBrand)
+		adminGroup.PUT("/brands/:id", handler.updateBrand)
+		adminGroup.DELETE("/brands/:id", handler.deleteBrand)
+		adminGroup.POST("/categories", handler.createCategory)
+		adminGroup.PUT("/categories/:id", handler.updateCategory)
+		adminGroup.DELETE("/categories/:id", handler.deleteCategory)
+		adminGroup.GET("/products/manage", handler.getProducts)
+		adminGroup.GET("/products/manage/:id", handler.getProductByID)
+		adminGroup.POST("/products", handler.createProduct)
+		adminGroup.PUT("/products/:id", handler.updateProduct)
+	}
+}
+
+func (c *productDelivery) getBrands(ctx *gin.Context) {
+	brands, err := c.productUC.GetBrands(ctx.Request.Context())
+	if err != nil {
+		json.AbortWithError(ctx, err, "43")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, brands, "success", "43", "01")
+}
+
+func (c *productDelivery) getBrandBySlug(ctx *gin.Context) {
+	brand, err := c.productUC.GetBrandBySlug(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "44")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, brand, "success", "44", "01")
+}
+
+func (c *productDelivery) createBrand(ctx *gin.Context) {
+	var brandPayload *productDto.BrandRequest
+	if validationError := validation.BindJSON(ctx, &brandPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "45", "01")
+		return
+	}
+
+	brand, err := c.productUC.CreateBrand(ctx.Request.Context(), brandPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "45")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, brand, "success", "45", "02")
+}
+
+func (c *productDelivery) updateBrand(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var brandPayload *productDto.BrandRequest
+	if validationError := validation.BindJSON(ctx, &brandPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "46", "01")
+		return
+	}
+
+	brand, err := c.productUC.UpdateBrand(ctx.Request.Context(), ID, brandPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "46")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, brand, "success", "46", "02")
+}
+
+func (c *productDelivery) deleteBrand(ctx *gin.Context) {
+	err := c.productUC.DeleteBrand(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "47")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "47", "01")
+}
+
+func (c *productDelivery) getCategoryTree(ctx *gin.Context) {
+	categories, err := c.productUC.GetCategoryTree(ctx.Request.Context())
+	if err != nil {
+		json.AbortWithError(ctx, err, "48")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, categories, "success", "48", "01")
+}
+
+func (c *productDelivery) getCategoryBySlug(ctx *gin.Context) {
+	category, err := c.productUC.GetCategoryBySlug(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "49")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, category, "success", "49", "01")
+}
+
+func (c *productDelivery) createCategory(ctx *gin.Context) {
+	var categoryPayload *productDto.CategoryRequest
+	if validationError := validation.BindJSON(ctx, &categoryPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "50", "01")
+		return
+	}
+
+	category, err := c.productUC.CreateCategory(ctx.Request.Context(), categoryPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "50")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, category, "success", "50", "02")
+}
+
+func (c *productDelivery) updateCategory(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var categoryPayload *productDto.CategoryRequest
+	if validationError := validation.BindJSON(ctx, &categoryPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "51", "01")
+		return
+	}
+
+	category, err := c.productUC.UpdateCategory(ctx.Request.Context(), ID, categoryPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "51")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, category, "success", "51", "02")
+}
+
+func (c *productDelivery) deleteCategory(ctx *gin.Context) {
+	err := c.productUC.DeleteCategory(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "52")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "52", "01")
+}
+
+func (c *productDelivery) getActiveProducts(ctx *gin.Context) {
+	query, validationError := parseProductListQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "53", "01")
+		return
+	}
+	query.Status = entity.ProductStatusActive
+
+	c.getProductPage(ctx, query, "53")
+}
+
+func (c *productDelivery) getProducts(ctx *gin.Context) {
+	query, validationError := parseProductListQuery(ctx)
+	query.Status = entity.ProductStatus(ctx.Query("status"))
+	if query.Status != "" && !query.Status.IsValid() {
+		validationError = append(validationError, json.ValidationField{FieldName: "status", Message: "must be one of draft active archived"})
+	}
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "55", "01")
+		return
+	}
+
+	c.getProductPage(ctx, query, "55")
+}
+
+func (c *productDelivery) getProductPage(ctx *gin.Context, query *productDto.ProductListQuery, serviceCode string) {
+	products, total, err := c.productUC.GetProducts(ctx.Request.Context(), query)
+	if err != nil {
+		json.AbortWithError(ctx, err, serviceCode)
+		return
+	}
+
+	json.NewResponseSuccessPage(ctx, products, json.NewPaging(query.Page, query.Size, total, ""), "success", serviceCode, "02")
+}
+
+func (c *productDelivery) getActiveProductBySlug(ctx *gin.Context) {
+	p, err := c.productUC.GetActiveProductBySlug(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "54")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, p, "success", "54", "01")
+}
+
+func (c *productDelivery) getProductByID(ctx *gin.Context) {
+	p, err := c.productUC.GetProductByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "56")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, p, "success", "56", "01")
+}
+
+func (c *productDelivery) createProduct(ctx *gin.Context) {
+	var productPayload *productDto.ProductRequest
+	if validationError := validation.BindJSON(ctx, &productPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "57", "01")
+		return
+	}
+
+	p, err := c.productUC.CreateProduct(ctx.Request.Context(), productPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "57")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, p, "success", "57", "02")
+}
+
+func (c *productDelivery) updateProduct(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var productPayload *productDto.ProductRequest
+	if validationError := validation.BindJSON(ctx, &productPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "58", "01")
+		return
+	}
+
+	p, err := c.productUC.UpdateProduct(ctx.Request.Context(), ID, productPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "58")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, p, "success", "58", "02")
+}
+
+func parseProductListQuery(ctx *gin.Context) (*productDto.ProductListQuery, []json.ValidationField) {
+	var validationError []json.ValidationField
+	query := &productDto.ProductListQuery{
+		Search:       ctx.Query("q"),
+		BrandSlug:    ctx.Query("brand"),
+		CategorySlug: ctx.Query("category"),
+		Page:         1,
+		Size:         productDto.DefaultPageSize,
+	}
+
+	if value := ctx.Query("page"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 {
+			validationError = append(validationError, json.ValidationField{FieldName: "page", Message: "must be a positive number"})
+		}
+		query.Page = parsed
+	}
+
+	if value := ctx.Query("size"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 || parsed > productDto.MaxPageSize {
+			validationError = append(validationError, json.ValidationField{FieldName: "size", Message: fmt.Sprintf("must be between 1 and %d", productDto.MaxPageSize)})
+		}
+		query.Size = parsed
+	}
+
+	return query, validationError
+}
@@ -0,0 +1,20 @@
+package product
+
+import "clean-architecture/pkg/domainError"
+
+var (
+	ErrBrandNotFound    = domainError.NotFound("brand not found")
+	ErrCategoryNotFound = domainError.NotFound("category not found")
+	ErrProductNotFound  = domainError.NotFound("product not found")
+
+	ErrSlugInUse     = domainError.Conflict("slug already in use")
+	ErrBrandInUse    = domainError.Conflict("brand still has products")
+	ErrCategoryInUse = domainError.Conflict("category still has products or subcategories")
+
+	ErrUnknownBrand    = domainError.Validation("bad request", domainError.Field{Name: "brand_id", Message: "brand not found"})
+	ErrUnknownCategory = domainError.Validation("bad request", domainError.Field{Name: "category_id", Message: "category not found"})
+	ErrUnknownParent   = domainError.Validation("bad request", domainError.Field{Name: "parent_id", Message: "category not found"})
+	ErrCategoryCycle   = domainError.Validation("bad request", domainError.Field{Name: "parent_id", Message: "a category cannot be moved below itself"})
+	ErrEmptySlug       = domainError.Validation("bad request", domainError.Field{Name: "slug", Message: "required when the name has no letters or digits"})
+	ErrReservedSlug    = domainError.Validation("bad request", domainError.Field{Name: "slug", Message: "reserved, choose another slug"})
+)
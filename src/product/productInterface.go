@@ -0,0 +1,45 @@
+package product
+
+import (
+	"clean-architecture/model/dto/productDto"
+	"clean-architecture/model/entity"
+	"context"
+)
+
+type ProductRepository interface {
+	CreateBrand(ctx context.Context, brand *entity.Brand) (string, error)
+	GetBrands(ctx context.Context) ([]*entity.Brand, error)
+	GetBrandByID(ctx context.Context, id string) (*entity.Brand, error)
+	GetBrandBySlug(ctx context.Context, slug string) (*entity.Brand, error)
+	UpdateBrand(ctx context.Context, brand *entity.Brand) error
+	DeleteBrand(ctx context.Context, id string) error
+	CreateCategory(ctx context.Context, category *entity.Category) (string, error)
+	GetCategories(ctx context.Context) ([]*entity.Category, error)
+	GetCategoryByID(ctx context.Context, id string) (*entity.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	DeleteCategory(ctx context.Context, id string) error
+	CreateProduct(ctx context.Context, product *entity.Product) (string, error)
+	GetProductByID(ctx context.Context, id string) (*entity.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*entity.Product, error)
+	GetProducts(ctx context.Context, query *productDto.ProductListQuery) ([]*entity.Product, int, error)
+	UpdateProduct(ctx context.Context, product *entity.Product) error
+}
+
+type ProductUseCase interface {
+	CreateBrand(ctx context.Context, brand *productDto.BrandRequest) (*entity.Brand, error)
+	GetBrands(ctx context.Context) ([]*entity.Brand, error)
+	GetBrandBySlug(ctx context.Context, slug string) (*entity.Brand, error)
+	UpdateBrand(ctx context.Context, id string, brand *productDto.BrandRequest) (*entity.Brand, error)
+	DeleteBrand(ctx context.Context, id string) error
+	CreateCategory(ctx context.Context, category *productDto.CategoryRequest) (*entity.Category, error)
+	GetCategoryTree(ctx context.Context) ([]*entity.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, id string, category *productDto.CategoryRequest) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, id string) error
+	CreateProduct(ctx context.Context, product *productDto.ProductRequest) (*entity.Product, error)
+	GetProducts(ctx context.Context, query *productDto.ProductListQuery) ([]*entity.Product, int, error)
+	GetProductByID(ctx context.Context, id string) (*entity.Product, error)
+	GetActiveProductBySlug(ctx context.Context, slug string) (*entity.Product, error)
+	UpdateProduct(ctx context.Context, id string, product *productDto.ProductRequest) (*entity.Product, error)
+}
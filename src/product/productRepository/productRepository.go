@@ -0,0 +1,414 @@
+package productRepository
+
+import (
+	"clean-architecture/model/dto/productDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/product"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type productRepository struct {
+	db *sql.DB
+}
+
+func NewProductRepository(db *sql.DB) product.ProductRepository {
+	return &productRepository{db}
+}
+
+func (repo *productRepository) CreateBrand(ctx context.Context, brand *entity.Brand) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO brands (name, slug, description) VALUES ($1, $2, $3) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, brand.Name, brand.Slug, brand.Description).Scan(&id)
+	if err != nil {
+		if isPqError(err, pqUniqueViolation) {
+			return "", product.ErrSlugInUse
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *productRepository) GetBrands(ctx context.Context) ([]*entity.Brand, error) {
+	sqlQuery := `SELECT id, name, slug, description, created_at, updated_at FROM brands ORDER BY name`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brands := []*entity.Brand{}
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+
+	return brands, rows.Err()
+}
+
+func (repo *productRepository) GetBrandByID(ctx context.Context, id string) (*entity.Brand, error) {
+	sqlQuery := `SELECT id, name, slug, description, created_at, updated_at FROM brands WHERE id = $1`
+	brand, err := scanBrand(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, notFound(err, product.ErrBrandNotFound)
+	}
+
+	return brand, nil
+}
+
+func (repo *productRepository) GetBrandBySlug(ctx context.Context, slug string) (*entity.Brand, error) {
+	sqlQuery := `SELECT id, name, slug, description, created_at, updated_at FROM brands WHERE slug = $1`
+	brand, err := scanBrand(repo.db.QueryRowContext(ctx, sqlQuery, slug))
+	if err != nil {
+		return nil, notFound(err, product.ErrBrandNotFound)
+	}
+
+	return brand, nil
+}
+
+func (repo *productRepository) UpdateBrand(ctx context.Context, brand *entity.Brand) error {
+	query := `UPDATE brands SET name = $2, slug = $3, description = $4, updated_at = NOW() WHERE id = $1`
+	return execAffectingOne(ctx, repo.db, product.ErrBrandNotFound, query, brand.ID, brand.Name, brand.Slug, brand.Description)
+}
+
+func (repo *productRepository) DeleteBrand(ctx context.Context, id string) error {
+	err := execAffectingOne(ctx, repo.db, product.ErrBrandNotFound, `DELETE FROM brands WHERE id = $1`, id)
+	if isPqError(err, pqForeignKeyViolation) {
+		return product.ErrBrandInUse
+	}
+	return err
+}
+
+func (repo *productRepository) CreateCategory(ctx context.Context, category *entity.Category) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO categories (parent_id, name, slug, description) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, category.ParentID, category.Name, category.Slug, category.Description).Scan(&id)
+	if err != nil {
+		return "", categoryWriteError(err)
+	}
+
+	return id, nil
+}
+
+func (repo *productRepository) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	sqlQuery := `SELECT id, parent_id, name, slug, description, created_at, updated_at FROM categories ORDER BY name`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (repo *productRepository) GetCategoryByID(ctx context.Context, id string) (*entity.Category, error) {
+	sqlQuery := `SELECT id, parent_id, name, slug, description, created_at, updated_at FROM categories WHERE id = $1`
+	category, err := scanCategory(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, notFound(err, product.ErrCategoryNotFound)
+	}
+
+	return category, nil
+}
+
+func (repo *productRepository) GetCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error) {
+	sqlQuery := `SELECT id, parent_id, name, slug, description, created_at, updated_at FROM categories WHERE slug = $1`
+	category, err := scanCategory(repo.db.QueryRowContext(ctx, sqlQuery, slug))
+	if err != nil {
+		return nil, notFound(err, product.ErrCategoryNotFound)
+	}
+
+	return category, nil
+}
+
+// UpdateCategory refuses to move a category below itself or one of its descendants.
+// Moves take a table lock so two concurrent moves cannot build a cycle between them.
+func (repo *productRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if category.ParentID != nil {
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return err
+		}
+
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories WHERE id = $1
+				UNION ALL
+				SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`, *category.ParentID, category.ID).Scan(&cycle)
+		if err != nil {
+			return notFound(err, product.ErrCategoryNotFound)
+		}
+		if cycle {
+			return product.ErrCategoryCycle
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE categories SET parent_id = $2, name = $3, slug = $4, description = $5, updated_at = NOW() WHERE id = $1`,
+		category.ID, category.ParentID, category.Name, category.Slug, category.Description)
+	if err != nil {
+		return notFound(categoryWriteError(err), product.ErrCategoryNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return product.ErrCategoryNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (repo *productRepository) DeleteCategory(ctx context.Context, id string) error {
+	err := execAffectingOne(ctx, repo.db, product.ErrCategoryNotFound, `DELETE FROM categories WHERE id = $1`, id)
+	if isPqError(err, pqForeignKeyViolation) {
+		return product.ErrCategoryInUse
+	}
+	return err
+}
+
+func (repo *productRepository) CreateProduct(ctx context.Context, p *entity.Product) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO products (brand_id, category_id, name, slug, description, status, price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, p.BrandID, p.CategoryID, p.Name, p.Slug, p.Description, p.Status, p.Price).Scan(&id)
+	if err != nil {
+		return "", productWriteError(err)
+	}
+
+	return id, nil
+}
+
+func (repo *productRepository) GetProductByID(ctx context.Context, id string) (*entity.Product, error) {
+	sqlQuery := `SELECT id, brand_id, category_id, name, slug, description, status, price, created_at, updated_at
+		FROM products WHERE id = $1`
+	p, err := scanProduct(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, notFound(err, product.ErrProductNotFound)
+	}
+
+	return p, nil
+}
+
+func (repo *productRepository) GetProductBySlug(ctx context.Context, slug string) (*entity.Product, error) {
+	sqlQuery := `SELECT id, brand_id, category_id, name, slug, description, status, price, created_at, updated_at
+		FROM products WHERE slug = $1`
+	p, err := scanProduct(repo.db.QueryRowContext(ctx, sqlQuery, slug))
+	if err != nil {
+		return nil, notFound(err, product.ErrProductNotFound)
+	}
+
+	return p, nil
+}
+
+// GetProducts returns one page of the filtered products, newest first, together with the number of matches
+func (repo *productRepository) GetProducts(ctx context.Context, query *productDto.ProductListQuery) ([]*entity.Product, int, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = "+arg(query.Status))
+	}
+	if query.Search != "" {
+		conditions = append(conditions, "name ILIKE "+arg(containsPattern(query.Search))+` ESCAPE '\'`)
+	}
+	if query.BrandSlug != "" {
+		conditions = append(conditions, "brand_id = (SELECT id FROM brands WHERE slug = "+arg(query.BrandSlug)+")")
+	}
+	if query.CategorySlug != "" {
+		conditions = append(conditions, `category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE slug = `+arg(query.CategorySlug)+`
+				UNION ALL
+				SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree)`)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	count := 0
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products"+where, args...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sqlQuery := "SELECT id, brand_id, category_id, name, slug, description, status, price, created_at, updated_at FROM products" +
+		where + " ORDER BY created_at DESC, id DESC LIMIT " + arg(query.Size) + " OFFSET " + arg((query.Page-1)*query.Size)
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []*entity.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return products, count, nil
+}
+
+func (repo *productRepository) UpdateProduct(ctx context.Context, p *entity.Product) error {
+	query := `UPDATE products SET brand_id = $2, category_id = $3, name = $4, slug = $5, description = $6, status = $7, price = $8, updated_at = NOW()
+		WHERE id = $1`
+	err := execAffectingOne(ctx, repo.db, product.ErrProductNotFound, query,
+		p.ID, p.BrandID, p.CategoryID, p.Name, p.Slug, p.Description, p.Status, p.Price)
+	return productWriteError(err)
+}
+
+// execAffectingOne reports notFoundErr when the statement matched nothing
+func execAffectingOne(ctx context.Context, db *sql.DB, notFoundErr error, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return notFound(err, notFoundErr)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFoundErr
+	}
+
+	return nil
+}
+
+// categoryWriteError maps constraint violations of a category insert or update
+func categoryWriteError(err error) error {
+	switch {
+	case isPqError(err, pqUniqueViolation):
+		return product.ErrSlugInUse
+	case isPqError(err, pqForeignKeyViolation):
+		return product.ErrUnknownParent
+	}
+	return err
+}
+
+// productWriteError maps constraint violations of a product insert or update
+func productWriteError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == pqUniqueViolation:
+		return product.ErrSlugInUse
+	case pqErr.Code == pqForeignKeyViolation && pqErr.Constraint == "products_brand_id_fkey":
+		return product.ErrUnknownBrand
+	case pqErr.Code == pqForeignKeyViolation && pqErr.Constraint == "products_category_id_fkey":
+		return product.ErrUnknownCategory
+	}
+	return err
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqForeignKeyViolation       = "23503"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+// containsPattern turns user input into an ILIKE pattern matching it anywhere, with its wildcards escaped
+func containsPattern(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value) + "%"
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBrand(row rowScanner) (*entity.Brand, error) {
+	brand := new(entity.Brand)
+	err := row.Scan(&brand.ID, &brand.Name, &brand.Slug, &brand.Description, &brand.CreatedAt, &brand.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return brand, nil
+}
+
+func scanCategory(row rowScanner) (*entity.Category, error) {
+	category := new(entity.Category)
+	err := row.Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug, &category.Description, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func scanProduct(row rowScanner) (*entity.Product, error) {
+	p := new(entity.Product)
+	err := row.Scan(
+		&p.ID,
+		&p.BrandID,
+		&p.CategoryID,
+		&p.Name,
+		&p.Slug,
+		&p.Description,
+		&p.Status,
+		&p.Price,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,271 @@
+package productUseCase
+
+import (
+	"clean-architecture/model/dto/productDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/product"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+type ProductUC struct {
+	productRepo product.ProductRepository
+}
+
+func NewProductUseCase(productRepo product.ProductRepository) product.ProductUseCase {
+	return &ProductUC{productRepo}
+}
+
+func (useCase *ProductUC) CreateBrand(ctx context.Context, newBrand *productDto.BrandRequest) (*entity.Brand, error) {
+	slug, err := resolveSlug(newBrand.Slug, newBrand.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := useCase.productRepo.CreateBrand(ctx, &entity.Brand{
+		Name:        newBrand.Name,
+		Slug:        slug,
+		Description: newBrand.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetBrandByID(ctx, id)
+}
+
+func (useCase *ProductUC) GetBrands(ctx context.Context) ([]*entity.Brand, error) {
+	return useCase.productRepo.GetBrands(ctx)
+}
+
+func (useCase *ProductUC) GetBrandBySlug(ctx context.Context, slug string) (*entity.Brand, error) {
+	return useCase.productRepo.GetBrandBySlug(ctx, slug)
+}
+
+func (useCase *ProductUC) UpdateBrand(ctx context.Context, id string, updated *productDto.BrandRequest) (*entity.Brand, error) {
+	slug, err := resolveSlug(updated.Slug, updated.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = useCase.productRepo.UpdateBrand(ctx, &entity.Brand{
+		ID:          id,
+		Name:        updated.Name,
+		Slug:        slug,
+		Description: updated.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetBrandByID(ctx, id)
+}
+
+// DeleteBrand only removes brands without products, archive the products first
+func (useCase *ProductUC) DeleteBrand(ctx context.Context, id string) error {
+	return useCase.productRepo.DeleteBrand(ctx, id)
+}
+
+func (useCase *ProductUC) CreateCategory(ctx context.Context, newCategory *productDto.CategoryRequest) (*entity.Category, error) {
+	slug, err := resolveSlug(newCategory.Slug, newCategory.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := useCase.productRepo.CreateCategory(ctx, &entity.Category{
+		ParentID:    newCategory.ParentID,
+		Name:        newCategory.Name,
+		Slug:        slug,
+		Description: newCategory.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetCategoryByID(ctx, id)
+}
+
+// GetCategoryTree returns the root categories with their subcategories nested below them
+func (useCase *ProductUC) GetCategoryTree(ctx context.Context) ([]*entity.Category, error) {
+	categories, err := useCase.productRepo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func buildCategoryTree(categories []*entity.Category) []*entity.Category {
+	byID := make(map[string]*entity.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	roots := []*entity.Category{}
+	for _, category := range categories {
+		parent, ok := byID[deref(category.ParentID)]
+		if !ok {
+			roots = append(roots, category)
+			continue
+		}
+		parent.Children = append(parent.Children, category)
+	}
+
+	return roots
+}
+
+// GetCategoryBySlug returns the category with its direct subcategories
+func (useCase *ProductUC) GetCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error) {
+	category, err := useCase.productRepo.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := useCase.productRepo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range categories {
+		if deref(child.ParentID) == category.ID {
+			category.Children = append(category.Children, child)
+		}
+	}
+
+	return category, nil
+}
+
+func (useCase *ProductUC) UpdateCategory(ctx context.Context, id string, updated *productDto.CategoryRequest) (*entity.Category, error) {
+	if updated.ParentID != nil && *updated.ParentID == id {
+		return nil, product.ErrCategoryCycle
+	}
+
+	slug, err := resolveSlug(updated.Slug, updated.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = useCase.productRepo.UpdateCategory(ctx, &entity.Category{
+		ID:          id,
+		ParentID:    updated.ParentID,
+		Name:        updated.Name,
+		Slug:        slug,
+		Description: updated.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetCategoryByID(ctx, id)
+}
+
+// DeleteCategory only removes leaf categories without products
+func (useCase *ProductUC) DeleteCategory(ctx context.Context, id string) error {
+	return useCase.productRepo.DeleteCategory(ctx, id)
+}
+
+func (useCase *ProductUC) CreateProduct(ctx context.Context, newProduct *productDto.ProductRequest) (*entity.Product, error) {
+	p, err := productFromRequest(newProduct)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := useCase.productRepo.CreateProduct(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetProductByID(ctx, id)
+}
+
+func (useCase *ProductUC) GetProducts(ctx context.Context, query *productDto.ProductListQuery) ([]*entity.Product, int, error) {
+	return useCase.productRepo.GetProducts(ctx, query)
+}
+
+func (useCase *ProductUC) GetProductByID(ctx context.Context, id string) (*entity.Product, error) {
+	return useCase.productRepo.GetProductByID(ctx, id)
+}
+
+// GetActiveProductBySlug hides drafts and archived products from the storefront as if they did not exist
+func (useCase *ProductUC) GetActiveProductBySlug(ctx context.Context, slug string) (*entity.Product, error) {
+	p, err := useCase.productRepo.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entity.ProductStatusActive {
+		return nil, product.ErrProductNotFound
+	}
+
+	return p, nil
+}
+
+func (useCase *ProductUC) UpdateProduct(ctx context.Context, id string, updated *productDto.ProductRequest) (*entity.Product, error) {
+	p, err := productFromRequest(updated)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = id
+
+	if err := useCase.productRepo.UpdateProduct(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return useCase.productRepo.GetProductByID(ctx, id)
+}
+
+// reservedProductSlugs are path segments of the admin product routes, a product with one of them could not be fetched by slug
+var reservedProductSlugs = []string{"manage"}
+
+func productFromRequest(request *productDto.ProductRequest) (*entity.Product, error) {
+	slug, err := resolveSlug(request.Slug, request.Name)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(reservedProductSlugs, slug) {
+		return nil, product.ErrReservedSlug
+	}
+
+	status := entity.ProductStatus(request.Status)
+	if status == "" {
+		status = entity.ProductStatusDraft
+	}
+
+	return &entity.Product{
+		BrandID:     request.BrandID,
+		CategoryID:  request.CategoryID,
+		Name:        request.Name,
+		Slug:        slug,
+		Description: request.Description,
+		Status:      status,
+		Price:       *request.Price,
+	}, nil
+}
+
+// resolveSlug keeps an explicit slug and otherwise derives one from the name
+func resolveSlug(slug, name string) (string, error) {
+	if slug != "" {
+		return slug, nil
+	}
+
+	slug = slugify(name)
+	if slug == "" {
+		return "", product.ErrEmptySlug
+	}
+	return slug, nil
+}
+
+// slugify lowercases name and joins its runs of ascii letters and digits with single hyphens
+func slugify(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(char rune) bool {
+		return char > unicode.MaxASCII || !(unicode.IsLetter(char) || unicode.IsDigit(char))
+	})
+	return strings.Join(words, "-")
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
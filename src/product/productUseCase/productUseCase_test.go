@@ -0,0 +1,96 @@
+package productUseCase
+
+import (
+	"clean-architecture/model/entity"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBuildCategoryTree(t *testing.T) {
+	category := func(id, parentID string) *entity.Category {
+		c := &entity.Category{ID: id}
+		if parentID != "" {
+			c.ParentID = &parentID
+		}
+		return c
+	}
+
+	tests := []struct {
+		name       string
+		categories []*entity.Category
+		// want maps every category id to the ids of its children, roots are listed under ""
+		want map[string][]string
+	}{
+		{
+			name:       "no categories",
+			categories: nil,
+			want:       map[string][]string{},
+		},
+		{
+			name:       "flat list",
+			categories: []*entity.Category{category("a", ""), category("b", "")},
+			want:       map[string][]string{"": {"a", "b"}},
+		},
+		{
+			name: "nested levels",
+			categories: []*entity.Category{
+				category("liquids", ""),
+				category("freebase", "liquids"),
+				category("salt", "liquids"),
+				category("salt-fruit", "salt"),
+				category("devices", ""),
+			},
+			want: map[string][]string{
+				"":        {"devices", "liquids"},
+				"liquids": {"freebase", "salt"},
+				"salt":    {"salt-fruit"},
+			},
+		},
+		{
+			name: "children listed before their parent",
+			categories: []*entity.Category{
+				category("salt-fruit", "salt"),
+				category("salt", "liquids"),
+				category("liquids", ""),
+			},
+			want: map[string][]string{
+				"":        {"liquids"},
+				"liquids": {"salt"},
+				"salt":    {"salt-fruit"},
+			},
+		},
+		{
+			name: "a missing parent makes the category a root",
+			categories: []*entity.Category{
+				category("salt", "gone"),
+				category("salt-fruit", "salt"),
+			},
+			want: map[string][]string{
+				"":     {"salt"},
+				"salt": {"salt-fruit"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string][]string{}
+			var walk func(parentID string, categories []*entity.Category)
+			walk = func(parentID string, categories []*entity.Category) {
+				for _, c := range categories {
+					got[parentID] = append(got[parentID], c.ID)
+					walk(c.ID, c.Children)
+				}
+			}
+			walk("", buildCategoryTree(tt.categories))
+
+			for _, ids := range got {
+				sort.Strings(ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildCategoryTree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
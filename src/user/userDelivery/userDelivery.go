@@ -1,192 +1,755 @@
 package userDelivery
 
 import (
+	"clean-architecture/model/dto/cartDto"
 	"clean-architecture/model/dto/json"
 	"clean-architecture/model/dto/userDto"
+	"clean-architecture/model/entity"
 	"clean-architecture/pkg/middleware"
 	"clean-architecture/pkg/validation"
 	"clean-architecture/src/user"
 	"clean-architecture/utils"
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
+// GuestCartMerger moves the cart a visitor filled before logging in into the cart of the user
+type GuestCartMerger interface {
+	MergeGuestCart(ctx context.Context, userID, cartToken string) error
+}
+
 type userDelivery struct {
-	userUC user.UserUseCase
+	userUC     user.UserUseCase
+	cartMerger GuestCartMerger
 }
 
-func NewUserDelivery(v1Group *gin.RouterGroup, userUC user.UserUseCase) {
+func NewUserDelivery(v1Group *gin.RouterGroup, userUC user.UserUseCase, clientAuth gin.HandlerFunc, cartMerger GuestCartMerger) {
 	handler := userDelivery{
-		userUC: userUC,
+		userUC:     userUC,
+		cartMerger: cartMerger,
 	}
 
-	// Group for operations that require Basic Auth
+	// Group for operations that require client Basic Auth
 	basicAuthGroup := v1Group.Group("/users")
 	{
-		basicAuthGroup.POST("/login", middleware.BasicAuthEncode, handler.loginUser)
+		basicAuthGroup.POST("/login", clientAuth, handler.loginUser)
+		basicAuthGroup.POST("/login/mfa", clientAuth, handler.loginMfa)
 		basicAuthGroup.POST("/create", handler.registerUser)
+		basicAuthGroup.POST("/token/refresh", clientAuth, handler.refreshToken)
+		basicAuthGroup.POST("/verify-email", handler.verifyEmail)
+		basicAuthGroup.POST("/verify-email/resend", handler.resendVerificationEmail)
+		basicAuthGroup.POST("/password/forgot", handler.forgotPassword)
+		basicAuthGroup.POST("/password/reset", handler.resetPassword)
 	}
 
 	// Group for operations that require JWT Auth
-	jwtAuthGroup := v1Group.Group("/users", middleware.JwtAuth())
+	jwtAuthGroup := v1Group.Group("/users", middleware.JwtAuth(userUC))
 	{
-		jwtAuthGroup.GET("", handler.getUsers)
+		jwtAuthGroup.POST("/logout", handler.logoutUser)
+		jwtAuthGroup.POST("/age-verification/document", handler.submitAgeDocument)
+		jwtAuthGroup.POST("/mfa/enroll", handler.enrollMfa)
+		jwtAuthGroup.POST("/mfa/verify", handler.confirmMfa)
+		jwtAuthGroup.POST("/mfa/disable", handler.disableMfa)
+		jwtAuthGroup.GET("/me", handler.getMe)
+		jwtAuthGroup.PATCH("/me", handler.updateMe)
+		jwtAuthGroup.POST("/me/password", handler.changePassword)
+		jwtAuthGroup.DELETE("/me", handler.deleteMe)
+		jwtAuthGroup.GET("/me/export", handler.exportMe)
+		jwtAuthGroup.POST("/me/erasure", handler.requestErasure)
+		jwtAuthGroup.GET("/me/privacy-requests", handler.getMyPrivacyRequests)
+		jwtAuthGroup.GET("/privacy-requests", middleware.RequirePermission(entity.PermissionPrivacyManage), handler.getPrivacyRequests)
+		jwtAuthGroup.POST("/privacy-requests/:id/approve", middleware.RequirePermission(entity.PermissionPrivacyManage), handler.approveErasure)
+		jwtAuthGroup.POST("/privacy-requests/:id/reject", middleware.RequirePermission(entity.PermissionPrivacyManage), handler.rejectPrivacyRequest)
+		jwtAuthGroup.GET("", middleware.RequirePermission(entity.PermissionUserRead), handler.getUsers)
+		jwtAuthGroup.GET("/deleted", middleware.RequirePermission(entity.PermissionUserDelete), handler.getDeletedUsers)
+		jwtAuthGroup.POST("/:id/restore", middleware.RequirePermission(entity.PermissionUserDelete), handler.restoreUser)
+		jwtAuthGroup.DELETE("/:id/purge", middleware.RequirePermission(entity.PermissionUserDelete), handler.purgeUser)
+		// ownership or permission on /:id is decided by the use case
 		jwtAuthGroup.GET("/:id", handler.getUserByID)
 		jwtAuthGroup.PUT("/:id", handler.updateUser)
+		jwtAuthGroup.PATCH("/:id", handler.patchUser)
+		jwtAuthGroup.GET("/:id/export", handler.exportUser)
+		jwtAuthGroup.PUT("/:id/role", middleware.RequirePermission(entity.PermissionRoleAssign), handler.updateUserRole)
+		jwtAuthGroup.POST("/:id/unlock", middleware.RequirePermission(entity.PermissionUserWrite), handler.unlockUser)
+		jwtAuthGroup.PUT("/:id/age-verification", middleware.RequirePermission(entity.PermissionAgeVerify), handler.reviewAgeVerification)
 		jwtAuthGroup.DELETE("/:id", handler.deleteUser)
 	}
 }
 
 func (c *userDelivery) registerUser(ctx *gin.Context) {
 	var userPayload *userDto.CreateUserRequest
-	if err := ctx.ShouldBindJSON(&userPayload); err != nil {
-		validationError := validation.GetValidationError(err)
-		if len(validationError) > 0 {
-			json.NewResponseBadRequest(ctx, validationError, "bad request", "01", "01")
+	if validationError := validation.BindJSON(ctx, &userPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "01", "01")
+		return
+	}
+
+	err := c.userUC.CreateUser(ctx.Request.Context(), userPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "01")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "01", "04")
+}
+
+func (c *userDelivery) loginUser(ctx *gin.Context) {
+	var userPayload *userDto.LoginUserRequest
+	if validationError := validation.BindJSON(ctx, &userPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "02", "01")
+		return
+	}
+
+	user, err := c.userUC.Login(ctx.Request.Context(), userPayload.Email, userPayload.Password, ctx.ClientIP())
+	if err != nil {
+		json.AbortWithError(ctx, err, "02")
+		return
+	}
+
+	if err := c.userUC.CheckLoginAllowed(user); err != nil {
+		json.AbortWithError(ctx, err, "02")
+		return
+	}
+
+	if user.MfaEnabledAt != nil {
+		challenge, err := c.userUC.IssueMfaChallenge(user)
+		if err != nil {
+			json.NewResponseErrorFromCause(ctx, err, "02", "04")
 			return
 		}
+
+		json.NewResponseSuccess(ctx, challenge, "mfa required", "02", "07")
+		return
 	}
 
-	existingUser, err := c.userUC.GetUserByEmail(userPayload.Email)
-	if err != nil && err != sql.ErrNoRows {
-		json.NewResponseError(ctx, "Failed to query database", "01", "03")
+	token, err := c.userUC.IssueTokens(ctx.Request.Context(), user, false)
+	if err != nil {
+		json.AbortWithError(ctx, err, "02")
 		return
 	}
 
-	if existingUser != nil {
-		json.NewResponseForbidden(ctx, "email already in use", "01", "02")
+	c.mergeGuestCart(ctx, user.ID)
+	json.NewResponseSuccess(ctx, token, "success", "02", "05")
+}
+
+// mergeGuestCart carries the cart of the visitor over on login, a stale cart token never fails the login
+func (c *userDelivery) mergeGuestCart(ctx *gin.Context, userID string) {
+	cartToken := ctx.GetHeader(cartDto.CartTokenHeader)
+	if cartToken == "" {
 		return
 	}
 
-	if !c.userUC.IsValidPassword(userPayload.Password) {
-		json.NewResponseForbidden(ctx, "password must contain upper and lower case letters", "01", "03")
+	if err := c.cartMerger.MergeGuestCart(ctx.Request.Context(), userID, cartToken); err != nil {
+		log.Warn().Msg("mergeGuestCart.MergeGuestCart : " + userID + " " + err.Error())
+	}
+}
+
+func (c *userDelivery) getUsers(ctx *gin.Context) {
+	query, validationError := parseUserListQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "03", "03")
 		return
 	}
 
-	hashedPassword, err := c.userUC.HashPassword(userPayload.Password)
+	page, err := c.userUC.GetUsers(ctx.Request.Context(), query)
 	if err != nil {
-		json.NewResponseError(ctx, "Internal server error", "01", "04")
+		json.AbortWithError(ctx, err, "03")
+		return
 	}
 
-	err = c.userUC.CreateUser(&userDto.CreateUserRequest{
-		Email:    userPayload.Email,
-		FullName: userPayload.FullName,
-		Password: hashedPassword,
-	})
+	pageNumber := query.Page
+	if query.Cursor != "" {
+		pageNumber = 0
+	}
+	users := userDto.NewUserViews(middleware.GetActor(ctx), page.Users)
+	json.NewResponseSuccessPage(ctx, users, json.NewPaging(pageNumber, query.Size, page.Total, page.NextCursor), "success", "03", "02")
+}
+
+// parsePageQuery reads page and size, defaulting to the first page of DefaultPageSize
+func parsePageQuery(ctx *gin.Context) (int, int, []json.ValidationField) {
+	var validationError []json.ValidationField
+	page, size := 1, userDto.DefaultPageSize
+
+	if value := ctx.Query("page"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 {
+			validationError = append(validationError, json.ValidationField{FieldName: "page", Message: "must be a positive number"})
+		}
+		page = parsed
+	}
+
+	if value := ctx.Query("size"); value != "" {
+		parsed, err := utils.StrToInt(value)
+		if err != nil || parsed < 1 || parsed > userDto.MaxPageSize {
+			validationError = append(validationError, json.ValidationField{FieldName: "size", Message: fmt.Sprintf("must be between 1 and %d", userDto.MaxPageSize)})
+		}
+		size = parsed
+	}
+
+	return page, size, validationError
+}
+
+func parseUserListQuery(ctx *gin.Context) (*userDto.UserListQuery, []json.ValidationField) {
+	page, size, validationError := parsePageQuery(ctx)
+	query := &userDto.UserListQuery{
+		Email:      ctx.Query("email"),
+		FullName:   ctx.Query("fullname"),
+		Role:       ctx.Query("role"),
+		SortBy:     ctx.DefaultQuery("sort", "created_at"),
+		Descending: true,
+		Page:       page,
+		Size:       size,
+		Cursor:     ctx.Query("cursor"),
+	}
+
+	if !slices.Contains(userDto.UserSortFields, query.SortBy) {
+		validationError = append(validationError, json.ValidationField{FieldName: "sort", Message: "must be one of " + strings.Join(userDto.UserSortFields, " ")})
+	}
+
+	switch ctx.DefaultQuery("order", "desc") {
+	case "asc":
+		query.Descending = false
+	case "desc":
+	default:
+		validationError = append(validationError, json.ValidationField{FieldName: "order", Message: "must be asc or desc"})
+	}
+
+	if query.Role != "" && !entity.Role(query.Role).IsValid() {
+		validationError = append(validationError, json.ValidationField{FieldName: "role", Message: "unknown role"})
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseTimeQuery(ctx.Query("created_from"), false); err != nil {
+		validationError = append(validationError, json.ValidationField{FieldName: "created_from", Message: err.Error()})
+	}
+	if query.CreatedTo, err = parseTimeQuery(ctx.Query("created_to"), true); err != nil {
+		validationError = append(validationError, json.ValidationField{FieldName: "created_to", Message: err.Error()})
+	}
+
+	return query, validationError
+}
+
+// parseTimeQuery accepts RFC 3339 or a plain date, a plain date used as an upper bound covers that whole day
+func parseTimeQuery(value string, upperBound bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+
+	parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "01", "03")
+		return nil, errors.New("must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	if upperBound {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return &parsed, nil
+}
+
+func (c *userDelivery) getUserByID(ctx *gin.Context) {
+
+	ID := ctx.Param("id")
+
+	actor := middleware.GetActor(ctx)
+	foundUser, err := c.userUC.GetUserByID(ctx.Request.Context(), actor, ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "04")
 		return
 	}
 
-	json.NewResponseSuccess(ctx, nil, "success", "01", "04")
+	json.NewResponseSuccess(ctx, userDto.NewUserView(actor, foundUser), "success", "04", "02")
 }
 
-func (c *userDelivery) loginUser(ctx *gin.Context) {
-	var userPayload *userDto.LoginUserRequest
-	if err := ctx.ShouldBindJSON(&userPayload); err != nil {
-		validationError := validation.GetValidationError(err)
-		if len(validationError) > 0 {
-			json.NewResponseBadRequest(ctx, validationError, "bad request", "02", "01")
-			return
-		}
+func (c *userDelivery) updateUser(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	userPayload := &userDto.UpdateUserRequest{ID: ID}
+	if validationError := validation.BindJSON(ctx, &userPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "05", "01")
+		return
 	}
 
-	user, err := c.userUC.GetUserByEmail(userPayload.Email)
+	// the path decides which account is changed, never the body
+	userPayload.ID = ID
+
+	err := c.userUC.UpdateUser(ctx.Request.Context(), middleware.GetActor(ctx), userPayload)
 	if err != nil {
-		json.NewResponseForbidden(ctx, "invalid email", "02", "02")
+		json.AbortWithError(ctx, err, "05")
 		return
 	}
 
-	if !c.userUC.ComparePasswords(user.Password, []byte(userPayload.Password)) {
-		json.NewResponseForbidden(ctx, "invalid email or password", "02", "03")
+	json.NewResponseSuccess(ctx, nil, "success", "05", "02")
+}
+
+func (c *userDelivery) patchUser(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var patchPayload *userDto.PatchUserRequest
+	if validationError := validation.BindJSON(ctx, &patchPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "invalid merge patch document", "32", "01")
 		return
 	}
 
-	token, err := middleware.GenerateTokenJwt(user.ID, 3)
+	err := c.userUC.PatchUser(ctx.Request.Context(), middleware.GetActor(ctx), ID, patchPayload)
 	if err != nil {
-		json.NewResponseForbidden(ctx, "invalid email", "02", "04")
+		json.AbortWithError(ctx, err, "32")
 		return
 	}
 
-	json.NewResponseSuccess(ctx, token, "success", "02", "05")
+	json.NewResponseSuccess(ctx, nil, "success", "32", "02")
 }
 
-func (c *userDelivery) getUsers(ctx *gin.Context) {
+func (c *userDelivery) updateUserRole(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var rolePayload *userDto.UpdateUserRoleRequest
+	if validationError := validation.BindJSON(ctx, &rolePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "09", "01")
+		return
+	}
 
-	pageStr := ctx.Query("page")
-	limitStr := ctx.Query("size")
-	email := ctx.Query("email")
-	fullName := ctx.Query("fullname")
+	err := c.userUC.UpdateUserRole(ctx.Request.Context(), middleware.GetActor(ctx), ID, entity.Role(rolePayload.Role))
+	if err != nil {
+		json.AbortWithError(ctx, err, "09")
+		return
+	}
 
-	page, _ := utils.StrToInt(pageStr)
-	limit, _ := utils.StrToInt(limitStr)
+	json.NewResponseSuccess(ctx, nil, "success", "09", "04")
+}
 
-	users, count, err := c.userUC.GetUsers(page, limit, email, fullName)
+func (c *userDelivery) deleteUser(ctx *gin.Context) {
+
+	ID := ctx.Param("id")
+
+	err := c.userUC.DeleteUser(ctx.Request.Context(), middleware.GetActor(ctx), ID)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "03", "01")
+		json.AbortWithError(ctx, err, "06")
 		return
 	}
 
-	json.NewResponseSuccessPage(ctx, users, page, count, "success", "03", "02")
+	json.NewResponseSuccess(ctx, nil, "success", "06", "03")
 }
 
-func (c *userDelivery) getUserByID(ctx *gin.Context) {
+func (c *userDelivery) refreshToken(ctx *gin.Context) {
+	var tokenPayload *userDto.RefreshTokenRequest
+	if validationError := validation.BindJSON(ctx, &tokenPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "07", "01")
+		return
+	}
+
+	token, err := c.userUC.RefreshTokens(ctx.Request.Context(), tokenPayload.RefreshToken)
+	if err != nil {
+		json.AbortWithError(ctx, err, "07")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, token, "success", "07", "04")
+}
+
+func (c *userDelivery) logoutUser(ctx *gin.Context) {
+	familyID := ctx.GetString("familyID")
+	if familyID == "" {
+		json.NewResponseSuccess(ctx, nil, "success", "08", "02")
+		return
+	}
+
+	err := c.userUC.RevokeTokenFamily(ctx.Request.Context(), familyID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "08")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "08", "02")
+}
+
+func (c *userDelivery) submitAgeDocument(ctx *gin.Context) {
+	var documentPayload *userDto.SubmitAgeDocumentRequest
+	if validationError := validation.BindJSON(ctx, &documentPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "10", "01")
+		return
+	}
+
+	err := c.userUC.SubmitAgeDocument(ctx.Request.Context(), ctx.GetString("userID"), documentPayload.DocumentReference)
+	if err != nil {
+		json.AbortWithError(ctx, err, "10")
+		return
+	}
 
+	json.NewResponseSuccess(ctx, nil, "success", "10", "04")
+}
+
+func (c *userDelivery) reviewAgeVerification(ctx *gin.Context) {
 	ID := ctx.Param("id")
+	var reviewPayload *userDto.ReviewAgeVerificationRequest
+	if validationError := validation.BindJSON(ctx, &reviewPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "11", "01")
+		return
+	}
 
-	users, err := c.userUC.GetUserByID(ID)
+	err := c.userUC.ReviewAgeVerification(ctx.Request.Context(), middleware.GetActor(ctx), ID, entity.AgeVerificationStatus(reviewPayload.Status), reviewPayload.Note)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "04", "01")
+		json.AbortWithError(ctx, err, "11")
 		return
 	}
 
-	json.NewResponseSuccess(ctx, users, "success", "04", "02")
+	json.NewResponseSuccess(ctx, nil, "success", "11", "04")
 }
 
-func (c *userDelivery) updateUser(ctx *gin.Context) {
+func (c *userDelivery) verifyEmail(ctx *gin.Context) {
+	var verifyPayload *userDto.VerifyEmailRequest
+	if validationError := validation.BindJSON(ctx, &verifyPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "12", "01")
+		return
+	}
+
+	err := c.userUC.VerifyEmail(ctx.Request.Context(), verifyPayload.Token)
+	if err != nil {
+		json.AbortWithError(ctx, err, "12")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "12", "04")
+}
+
+func (c *userDelivery) resendVerificationEmail(ctx *gin.Context) {
+	var resendPayload *userDto.ResendVerificationRequest
+	if validationError := validation.BindJSON(ctx, &resendPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "13", "01")
+		return
+	}
+
+	err := c.userUC.SendVerificationEmail(ctx.Request.Context(), resendPayload.Email)
+	if err != nil {
+		json.AbortWithError(ctx, err, "13")
+		return
+	}
+
+	// same answer whether or not the address exists
+	json.NewResponseSuccess(ctx, nil, "if the email is registered and unverified, a verification link was sent", "13", "03")
+}
+
+func (c *userDelivery) forgotPassword(ctx *gin.Context) {
+	var forgotPayload *userDto.ForgotPasswordRequest
+	if validationError := validation.BindJSON(ctx, &forgotPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "14", "01")
+		return
+	}
+
+	err := c.userUC.ForgotPassword(ctx.Request.Context(), forgotPayload.Email)
+	if err != nil {
+		json.AbortWithError(ctx, err, "14")
+		return
+	}
+
+	// same answer whether or not the address exists
+	json.NewResponseSuccess(ctx, nil, "if the email is registered, a reset link was sent", "14", "03")
+}
+
+func (c *userDelivery) resetPassword(ctx *gin.Context) {
+	var resetPayload *userDto.ResetPasswordRequest
+	if validationError := validation.BindJSON(ctx, &resetPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "15", "01")
+		return
+	}
+
+	err := c.userUC.ResetPassword(ctx.Request.Context(), resetPayload.Token, resetPayload.Password)
+	if err != nil {
+		json.AbortWithError(ctx, err, "15")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "15", "05")
+}
+
+func (c *userDelivery) unlockUser(ctx *gin.Context) {
 	ID := ctx.Param("id")
-	userPayload := &userDto.UpdateUserRequest{}
-	userPayload.ID = ID
-	if err := ctx.ShouldBindJSON(&userPayload); err != nil {
-		validationError := validation.GetValidationError(err)
-		if len(validationError) > 0 {
-			json.NewResponseBadRequest(ctx, validationError, "bad request", "05", "01")
-			return
-		}
+
+	err := c.userUC.UnlockLogin(ctx.Request.Context(), middleware.GetActor(ctx), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "16")
+		return
 	}
 
-	hashedPassword, err := c.userUC.HashPassword(userPayload.Password)
+	json.NewResponseSuccess(ctx, nil, "success", "16", "03")
+}
+
+func (c *userDelivery) loginMfa(ctx *gin.Context) {
+	var mfaPayload *userDto.LoginMfaRequest
+	if validationError := validation.BindJSON(ctx, &mfaPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "17", "01")
+		return
+	}
+
+	loginUser, err := c.userUC.LoginMfa(ctx.Request.Context(), mfaPayload.MfaToken, mfaPayload.Code, ctx.ClientIP())
 	if err != nil {
-		json.NewResponseError(ctx, "Internal server error", "05", "02")
+		json.AbortWithError(ctx, err, "17")
+		return
 	}
-	userPayload.Password = hashedPassword
 
-	err = c.userUC.UpdateUser(userPayload)
+	token, err := c.userUC.IssueTokens(ctx.Request.Context(), loginUser, true)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "05", "03")
+		json.AbortWithError(ctx, err, "17")
 		return
 	}
 
-	json.NewResponseSuccess(ctx, nil, "success", "05", "02")
+	c.mergeGuestCart(ctx, loginUser.ID)
+	json.NewResponseSuccess(ctx, token, "success", "17", "05")
 }
 
-func (c *userDelivery) deleteUser(ctx *gin.Context) {
+func (c *userDelivery) enrollMfa(ctx *gin.Context) {
+	enrollment, err := c.userUC.EnrollMfa(ctx.Request.Context(), ctx.GetString("userID"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "18")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, enrollment, "success", "18", "03")
+}
+
+func (c *userDelivery) confirmMfa(ctx *gin.Context) {
+	var codePayload *userDto.MfaCodeRequest
+	if validationError := validation.BindJSON(ctx, &codePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "19", "01")
+		return
+	}
+
+	recoveryCodes, err := c.userUC.ConfirmMfa(ctx.Request.Context(), ctx.GetString("userID"), codePayload.Code)
+	if err != nil {
+		json.AbortWithError(ctx, err, "19")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, recoveryCodes, "success", "19", "05")
+}
+
+func (c *userDelivery) disableMfa(ctx *gin.Context) {
+	var codePayload *userDto.MfaCodeRequest
+	if validationError := validation.BindJSON(ctx, &codePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "20", "01")
+		return
+	}
+
+	err := c.userUC.DisableMfa(ctx.Request.Context(), ctx.GetString("userID"), codePayload.Code)
+	if err != nil {
+		json.AbortWithError(ctx, err, "20")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "20", "05")
+}
+
+func (c *userDelivery) getMe(ctx *gin.Context) {
+	actor := middleware.GetActor(ctx)
+
+	me, err := c.userUC.GetUserByID(ctx.Request.Context(), actor, actor.ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "28")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, userDto.NewUserDetailResponse(me), "success", "28", "02")
+}
+
+func (c *userDelivery) updateMe(ctx *gin.Context) {
+	var patchPayload *userDto.PatchUserRequest
+	if validationError := validation.BindJSON(ctx, &patchPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "invalid merge patch document", "29", "01")
+		return
+	}
+
+	actor := middleware.GetActor(ctx)
+	err := c.userUC.PatchUser(ctx.Request.Context(), actor, actor.ID, patchPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "29")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "29", "02")
+}
+
+func (c *userDelivery) changePassword(ctx *gin.Context) {
+	var passwordPayload *userDto.ChangePasswordRequest
+	if validationError := validation.BindJSON(ctx, &passwordPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "30", "01")
+		return
+	}
+
+	err := c.userUC.ChangePassword(ctx.Request.Context(), ctx.GetString("userID"), ctx.GetString("familyID"), passwordPayload.CurrentPassword, passwordPayload.NewPassword)
+	if err != nil {
+		json.AbortWithError(ctx, err, "30")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "30", "05")
+}
+
+func (c *userDelivery) deleteMe(ctx *gin.Context) {
+	var deletePayload *userDto.DeleteAccountRequest
+	if validationError := validation.BindJSON(ctx, &deletePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "31", "01")
+		return
+	}
+
+	err := c.userUC.DeleteOwnAccount(ctx.Request.Context(), ctx.GetString("userID"), deletePayload.Password)
+	if err != nil {
+		json.AbortWithError(ctx, err, "31")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "31", "04")
+}
+
+func (c *userDelivery) getDeletedUsers(ctx *gin.Context) {
+	page, size, validationError := parsePageQuery(ctx)
+	if len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "33", "03")
+		return
+	}
+
+	users, count, err := c.userUC.GetDeletedUsers(ctx.Request.Context(), page, size)
+	if err != nil {
+		json.AbortWithError(ctx, err, "33")
+		return
+	}
 
+	json.NewResponseSuccessPage(ctx, userDto.NewUserViews(middleware.GetActor(ctx), users), json.NewPaging(page, size, count, ""), "success", "33", "02")
+}
+
+func (c *userDelivery) restoreUser(ctx *gin.Context) {
 	ID := ctx.Param("id")
-	IDLogged, _ := ctx.Get("userID")
-	fmt.Println(IDLogged, ID)
-	if ID == IDLogged.(string) {
-		json.NewResponseForbidden(ctx, "cant delete yourself", "06", "01")
+
+	err := c.userUC.RestoreUser(ctx.Request.Context(), middleware.GetActor(ctx), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "34")
 		return
 	}
 
-	err := c.userUC.DeleteUser(ID)
+	json.NewResponseSuccess(ctx, nil, "success", "34", "04")
+}
+
+func (c *userDelivery) purgeUser(ctx *gin.Context) {
+	ID := ctx.Param("id")
+
+	err := c.userUC.PurgeUser(ctx.Request.Context(), middleware.GetActor(ctx), ID)
 	if err != nil {
-		json.NewResponseError(ctx, err.Error(), "06", "02")
+		json.AbortWithError(ctx, err, "35")
 		return
 	}
 
-	json.NewResponseSuccess(ctx, nil, "success", "06", "03")
+	json.NewResponseSuccess(ctx, nil, "success", "35", "04")
+}
+
+func (c *userDelivery) exportMe(ctx *gin.Context) {
+	c.exportUserData(ctx, ctx.GetString("userID"), "36")
+}
+
+func (c *userDelivery) exportUser(ctx *gin.Context) {
+	c.exportUserData(ctx, ctx.Param("id"), "37")
+}
+
+// exportUserData answers with a single JSON file, or with one JSON file per section when format=zip
+func (c *userDelivery) exportUserData(ctx *gin.Context, ID, serviceCode string) {
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		json.NewResponseBadRequest(ctx, []json.ValidationField{{FieldName: "format", Message: "must be json or zip"}}, "bad request", serviceCode, "01")
+		return
+	}
+
+	export, err := c.userUC.ExportUserData(ctx.Request.Context(), middleware.GetActor(ctx), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, serviceCode)
+		return
+	}
+
+	filename := "user-data-" + export.Profile.ID
+	if format == "json" {
+		json.NewResponseAttachment(ctx, filename+".json", export)
+		return
+	}
+
+	json.NewResponseArchive(ctx, filename+".zip", []json.ArchiveFile{
+		{Name: "profile.json", Data: export.Profile},
+		{Name: "age_verifications.json", Data: export.AgeVerifications},
+		{Name: "sessions.json", Data: export.Sessions},
+		{Name: "password_resets.json", Data: export.PasswordResets},
+		{Name: "changes.json", Data: export.Changes},
+		{Name: "privacy_requests.json", Data: export.PrivacyRequests},
+		{Name: "login_attempts.json", Data: export.LoginAttempts},
+		{Name: "orders.json", Data: export.Orders},
+		{Name: "cart.json", Data: export.Cart},
+		{Name: "export.json", Data: gin.H{"exported_at": export.ExportedAt, "user_id": export.Profile.ID}},
+	})
+}
+
+func (c *userDelivery) requestErasure(ctx *gin.Context) {
+	var erasurePayload *userDto.DeleteAccountRequest
+	if validationError := validation.BindJSON(ctx, &erasurePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "38", "01")
+		return
+	}
+
+	request, err := c.userUC.RequestErasure(ctx.Request.Context(), ctx.GetString("userID"), erasurePayload.Password)
+	if err != nil {
+		json.AbortWithError(ctx, err, "38")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, request, "erasure requested", "38", "05")
+}
+
+func (c *userDelivery) getMyPrivacyRequests(ctx *gin.Context) {
+	requests, err := c.userUC.GetPrivacyRequests(ctx.Request.Context(), ctx.GetString("userID"), "")
+	if err != nil {
+		json.AbortWithError(ctx, err, "39")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, requests, "success", "39", "02")
+}
+
+func (c *userDelivery) getPrivacyRequests(ctx *gin.Context) {
+	status := entity.PrivacyRequestStatus(ctx.Query("status"))
+
+	requests, err := c.userUC.GetPrivacyRequests(ctx.Request.Context(), ctx.Query("user_id"), status)
+	if err != nil {
+		json.AbortWithError(ctx, err, "40")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, requests, "success", "40", "02")
+}
+
+func (c *userDelivery) approveErasure(ctx *gin.Context) {
+	ID := ctx.Param("id")
+
+	err := c.userUC.ApproveErasure(ctx.Request.Context(), middleware.GetActor(ctx), ID)
+	if err != nil {
+		json.AbortWithError(ctx, err, "41")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "41", "04")
+}
+
+func (c *userDelivery) rejectPrivacyRequest(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var rejectPayload *userDto.RejectPrivacyRequest
+	if validationError := validation.BindJSON(ctx, &rejectPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "42", "01")
+		return
+	}
+
+	err := c.userUC.RejectPrivacyRequest(ctx.Request.Context(), middleware.GetActor(ctx), ID, rejectPayload.Note)
+	if err != nil {
+		json.AbortWithError(ctx, err, "42")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, nil, "success", "42", "05")
 }
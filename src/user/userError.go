@@ -0,0 +1,48 @@
+package user
+
+import "clean-architecture/pkg/domainError"
+
+var (
+	ErrUserNotFound = domainError.NotFound("user not found")
+
+	ErrInvalidRefreshToken = domainError.Unauthorized("invalid refresh token")
+	ErrRefreshTokenReused  = domainError.Unauthorized("invalid refresh token")
+
+	ErrUnsupportedJurisdiction = domainError.Validation("bad request", domainError.Field{Name: "jurisdiction", Message: "unsupported jurisdiction"})
+	ErrUnderLegalAge           = domainError.Forbidden("under the legal age for this jurisdiction")
+	ErrAgeAlreadyVerified      = domainError.Conflict("age already verified")
+
+	ErrInvalidVerificationToken = domainError.Forbidden("invalid or expired verification token")
+	ErrEmailNotVerified         = domainError.Forbidden("email not verified")
+
+	ErrInvalidCredentials = domainError.Unauthorized("invalid email or password")
+	ErrLoginLocked        = domainError.TooManyRequests("too many failed login attempts, try again later")
+
+	ErrInvalidMfaCode     = domainError.Forbidden("invalid two-factor code")
+	ErrMfaAlreadyEnabled  = domainError.Conflict("two-factor authentication already enabled")
+	ErrMfaNotEnrolled     = domainError.Forbidden("two-factor authentication not enrolled")
+	ErrMfaRequiredForRole = domainError.Forbidden("two-factor authentication is mandatory for this role")
+	// ErrInvalidMfaLogin is the single answer of the second login step, whatever part of it failed
+	ErrInvalidMfaLogin = domainError.Unauthorized("invalid two-factor code")
+
+	ErrForbidden               = domainError.Forbidden("not allowed to manage this account")
+	ErrInvalidCurrentPassword  = domainError.Forbidden("current password is incorrect")
+	ErrSelfDeleteNeedsPassword = domainError.Forbidden("use DELETE /users/me to delete your own account")
+	ErrPasswordNeedsCurrent    = domainError.Forbidden("use POST /users/me/password to change your own password")
+	ErrSelfRoleChange          = domainError.Forbidden("cant change your own role")
+	ErrEmptyPatch              = domainError.Validation("no fields to update")
+
+	ErrEmailInUse     = domainError.Conflict("email already in use")
+	ErrPurgeBlocked   = domainError.Conflict("user is still referenced by other records and cannot be purged")
+	ErrUserNotDeleted = domainError.Conflict("user is not deleted")
+
+	ErrInvalidCursor = domainError.Validation("bad request", domainError.Field{Name: "cursor", Message: "invalid or mismatched cursor"})
+
+	ErrPrivacyRequestNotFound = domainError.NotFound("privacy request not found")
+	ErrErasurePending         = domainError.Conflict("an erasure request is already pending")
+	ErrPrivacyRequestHandled  = domainError.Conflict("privacy request already handled")
+	ErrUserAlreadyErased      = domainError.Conflict("user already erased, the request is marked as failed")
+
+	ErrInvalidResetToken = domainError.Forbidden("invalid or expired reset token")
+	ErrWeakPassword      = domainError.Validation("bad request", domainError.Field{Name: "password", Message: "password must contain upper and lower case letters, a digit and a symbol"})
+)
@@ -3,25 +3,96 @@ package user
 import (
 	"clean-architecture/model/dto/userDto"
 	"clean-architecture/model/entity"
+	"context"
+	"time"
 )
 
 type UserRepository interface {
-	CreateUser(user *userDto.CreateUserRequest) error
-	GetUserByEmail(email string) (*entity.User, error)
-	GetUserByID(id string) (*entity.User, error)
-	GetUsers(page, limit int, email, fullName string) ([]*entity.User, int, error)
-	UpdateUser(user *userDto.UpdateUserRequest) error
-	DeleteUser(id string) error
+	CreateUser(ctx context.Context, user *userDto.CreateUserRequest) (string, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetUserByID(ctx context.Context, id string) (*entity.User, error)
+	GetUsers(ctx context.Context, query *userDto.UserListQuery, after *userDto.UserCursor) ([]*entity.User, int, error)
+	PatchUser(ctx context.Context, id, changedBy string, patch *userDto.PatchUserRequest) error
+	UpdatePassword(ctx context.Context, id, hashedPassword, keepFamilyID string) error
+	UpdateUserRole(ctx context.Context, id string, role entity.Role) error
+	DeleteUser(ctx context.Context, id string) error
+	RevokeUserTokenFamilies(ctx context.Context, userID string) error
+	GetDeletedUsers(ctx context.Context, page, limit int) ([]*entity.User, int, error)
+	RestoreUser(ctx context.Context, id string) error
+	PurgeUser(ctx context.Context, id string) (string, error)
+	GetPurgeableUserIDs(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error)
+	CreatePrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) (string, error)
+	GetPrivacyRequestByID(ctx context.Context, id string) (*entity.PrivacyRequest, error)
+	GetPrivacyRequests(ctx context.Context, userID string, status entity.PrivacyRequestStatus) ([]*entity.PrivacyRequest, error)
+	CompletePrivacyRequest(ctx context.Context, id, handledBy string, status entity.PrivacyRequestStatus, note string) error
+	EraseUser(ctx context.Context, requestID, userID, handledBy string) (string, error)
+	GetUserDataExport(ctx context.Context, userID string) (*entity.DataExport, error)
+	CreateTokenFamily(ctx context.Context, userID string, mfaAuthenticated bool) (string, error)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	IsTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	CreateRefreshToken(ctx context.Context, familyID, tokenHash string, expiresAt time.Time) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error)
+	CreateAgeVerification(ctx context.Context, verification *entity.AgeVerification) error
+	GetAgeVerificationStatus(ctx context.Context, userID string) (entity.AgeVerificationStatus, error)
+	MarkEmailVerified(ctx context.Context, id, email string) (bool, error)
+	CreatePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	ResetPassword(ctx context.Context, tokenHash, hashedPassword string) (string, error)
+	SetMfaSecret(ctx context.Context, userID, secret string) (bool, error)
+	EnableMfa(ctx context.Context, userID string, recoveryCodeHashes []string) error
+	DisableMfa(ctx context.Context, userID string) error
+	UseMfaStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+}
+
+// LoginAttemptRepository stores failed login counters keyed by account or client ip
+type LoginAttemptRepository interface {
+	GetLoginAttempt(ctx context.Context, key string) (*entity.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
 }
 
 type UserUseCase interface {
-	CreateUser(user *userDto.CreateUserRequest) error
-	GetUserByEmail(email string) (*entity.User, error)
-	GetUsers(page, limit int, email, fullName string) ([]*entity.User, int, error)
-	GetUserByID(id string) (*entity.User, error)
-	UpdateUser(user *userDto.UpdateUserRequest) error
-	DeleteUser(id string) error
+	CreateUser(ctx context.Context, user *userDto.CreateUserRequest) error
+	GetUserByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetUsers(ctx context.Context, query *userDto.UserListQuery) (*userDto.UserPage, error)
+	GetUserByID(ctx context.Context, actor entity.Actor, id string) (*entity.User, error)
+	UpdateUser(ctx context.Context, actor entity.Actor, user *userDto.UpdateUserRequest) error
+	PatchUser(ctx context.Context, actor entity.Actor, id string, patch *userDto.PatchUserRequest) error
+	ChangePassword(ctx context.Context, userID, familyID, currentPassword, newPassword string) error
+	UpdateUserRole(ctx context.Context, actor entity.Actor, id string, role entity.Role) error
+	DeleteUser(ctx context.Context, actor entity.Actor, id string) error
+	DeleteOwnAccount(ctx context.Context, userID, password string) error
+	GetDeletedUsers(ctx context.Context, page, limit int) ([]*entity.User, int, error)
+	RestoreUser(ctx context.Context, actor entity.Actor, id string) error
+	PurgeUser(ctx context.Context, actor entity.Actor, id string) error
+	PurgeExpiredUsers(ctx context.Context) (int, error)
+	ExportUserData(ctx context.Context, actor entity.Actor, id string) (*entity.DataExport, error)
+	RequestErasure(ctx context.Context, userID, password string) (*entity.PrivacyRequest, error)
+	GetPrivacyRequests(ctx context.Context, userID string, status entity.PrivacyRequestStatus) ([]*entity.PrivacyRequest, error)
+	ApproveErasure(ctx context.Context, actor entity.Actor, requestID string) error
+	RejectPrivacyRequest(ctx context.Context, actor entity.Actor, requestID, note string) error
 	ComparePasswords(hashed string, plain []byte) bool
 	HashPassword(password string) (string, error)
 	IsValidPassword(password string) bool
+	IssueTokens(ctx context.Context, user *entity.User, mfaAuthenticated bool) (*userDto.TokenResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*userDto.TokenResponse, error)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	IsTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	SubmitAgeDocument(ctx context.Context, userID, documentReference string) error
+	ReviewAgeVerification(ctx context.Context, actor entity.Actor, userID string, status entity.AgeVerificationStatus, note string) error
+	IsAgeVerified(ctx context.Context, userID string) (bool, error)
+	SendVerificationEmail(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	Login(ctx context.Context, email, password, clientIP string) (*entity.User, error)
+	UnlockLogin(ctx context.Context, actor entity.Actor, id string) error
+	CheckLoginAllowed(user *entity.User) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	IssueMfaChallenge(user *entity.User) (*userDto.MfaChallengeResponse, error)
+	LoginMfa(ctx context.Context, mfaToken, code, clientIP string) (*entity.User, error)
+	EnrollMfa(ctx context.Context, userID string) (*userDto.MfaEnrollResponse, error)
+	ConfirmMfa(ctx context.Context, userID, code string) (*userDto.MfaRecoveryCodesResponse, error)
+	DisableMfa(ctx context.Context, userID, code string) error
 }
@@ -0,0 +1,87 @@
+package userRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/user"
+	"context"
+	"sync"
+	"time"
+)
+
+// loginAttemptMemoryRepository keeps counters in process, counters are lost on restart and not shared between instances
+type loginAttemptMemoryRepository struct {
+	mu          sync.Mutex
+	attempts    map[string]entity.LoginAttempt
+	lastEvicted time.Time
+}
+
+const memoryEvictionInterval = time.Minute
+
+func NewLoginAttemptMemoryRepository() user.LoginAttemptRepository {
+	return &loginAttemptMemoryRepository{attempts: make(map[string]entity.LoginAttempt)}
+}
+
+func (repo *loginAttemptMemoryRepository) GetLoginAttempt(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	attempt, ok := repo.attempts[key]
+	if !ok {
+		return &entity.LoginAttempt{Key: key}, nil
+	}
+	return &attempt, nil
+}
+
+func (repo *loginAttemptMemoryRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	attempt, ok := repo.attempts[key]
+	if !ok || attempt.LastFailureAt == nil || attempt.LastFailureAt.Before(now.Add(-window)) {
+		attempt = entity.LoginAttempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = &now
+	repo.attempts[key] = attempt
+
+	if now.Sub(repo.lastEvicted) > memoryEvictionInterval {
+		repo.evictExpired(now, window)
+		repo.lastEvicted = now
+	}
+
+	return attempt.Failures, nil
+}
+
+func (repo *loginAttemptMemoryRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	attempt := repo.attempts[key]
+	attempt.Key = key
+	attempt.LockedUntil = &until
+	repo.attempts[key] = attempt
+
+	return nil
+}
+
+func (repo *loginAttemptMemoryRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.attempts, key)
+	return nil
+}
+
+// evictExpired drops entries with neither a live window nor a live lock so the map cannot grow without bound
+func (repo *loginAttemptMemoryRepository) evictExpired(now time.Time, window time.Duration) {
+	for key, attempt := range repo.attempts {
+		if attempt.LastFailureAt != nil && attempt.LastFailureAt.After(now.Add(-window)) {
+			continue
+		}
+		if attempt.IsLocked(now) {
+			continue
+		}
+		delete(repo.attempts, key)
+	}
+}
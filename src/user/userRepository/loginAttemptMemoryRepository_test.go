@@ -0,0 +1,86 @@
+package userRepository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoginAttemptMemoryRepositoryWindow(t *testing.T) {
+	ctx := context.Background()
+	repo := NewLoginAttemptMemoryRepository()
+	window := 20 * time.Millisecond
+
+	for want := 1; want <= 3; want++ {
+		failures, err := repo.RecordLoginFailure(ctx, "account:a", window)
+		if err != nil {
+			t.Fatalf("RecordLoginFailure() error = %v", err)
+		}
+		if failures != want {
+			t.Fatalf("RecordLoginFailure() = %d, want %d", failures, want)
+		}
+	}
+
+	lockedUntil := time.Now().Add(time.Minute)
+	if err := repo.LockLogin(ctx, "account:a", lockedUntil); err != nil {
+		t.Fatalf("LockLogin() error = %v", err)
+	}
+
+	time.Sleep(2 * window)
+
+	// a failure after the window starts a new count but keeps the lock
+	failures, err := repo.RecordLoginFailure(ctx, "account:a", window)
+	if err != nil {
+		t.Fatalf("RecordLoginFailure() error = %v", err)
+	}
+	if failures != 1 {
+		t.Errorf("RecordLoginFailure() after the window = %d, want 1", failures)
+	}
+
+	attempt, err := repo.GetLoginAttempt(ctx, "account:a")
+	if err != nil {
+		t.Fatalf("GetLoginAttempt() error = %v", err)
+	}
+	if !attempt.IsLocked(time.Now()) {
+		t.Error("lock lost when the failure window restarted")
+	}
+
+	if err := repo.ResetLoginAttempts(ctx, "account:a"); err != nil {
+		t.Fatalf("ResetLoginAttempts() error = %v", err)
+	}
+	attempt, err = repo.GetLoginAttempt(ctx, "account:a")
+	if err != nil {
+		t.Fatalf("GetLoginAttempt() error = %v", err)
+	}
+	if attempt.Failures != 0 || attempt.IsLocked(time.Now()) {
+		t.Errorf("GetLoginAttempt() after reset = %+v, want no failures and no lock", attempt)
+	}
+}
+
+func TestLoginAttemptMemoryRepositoryKeysAreSeparate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewLoginAttemptMemoryRepository()
+
+	if _, err := repo.RecordLoginFailure(ctx, "account:a", time.Minute); err != nil {
+		t.Fatalf("RecordLoginFailure() error = %v", err)
+	}
+	if err := repo.LockLogin(ctx, "ip:10.0.0.1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("LockLogin() error = %v", err)
+	}
+
+	failures, err := repo.RecordLoginFailure(ctx, "account:b", time.Minute)
+	if err != nil {
+		t.Fatalf("RecordLoginFailure() error = %v", err)
+	}
+	if failures != 1 {
+		t.Errorf("RecordLoginFailure() of another key = %d, want 1", failures)
+	}
+
+	attempt, err := repo.GetLoginAttempt(ctx, "account:a")
+	if err != nil {
+		t.Fatalf("GetLoginAttempt() error = %v", err)
+	}
+	if attempt.IsLocked(time.Now()) {
+		t.Error("locking one key locked another")
+	}
+}
@@ -0,0 +1,70 @@
+package userRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/user"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) user.LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (repo *loginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	sqlQuery := `SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+	attempt := &entity.LoginAttempt{Key: key}
+	err := repo.db.QueryRowContext(ctx, sqlQuery, key).Scan(&attempt.Failures, &attempt.LastFailureAt, &attempt.LockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return attempt, nil
+		}
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// RecordLoginFailure bumps the counter, starting over when the previous failure is older than window
+func (repo *loginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	sqlQuery := `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING failures`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, key, window.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (repo *loginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	query := "UPDATE login_attempts SET locked_until = $2 WHERE key = $1"
+	_, err := repo.db.ExecContext(ctx, query, key, until)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *loginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	query := "DELETE FROM login_attempts WHERE key = $1"
+	_, err := repo.db.ExecContext(ctx, query, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
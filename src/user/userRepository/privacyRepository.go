@@ -0,0 +1,343 @@
+package userRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/user"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const privacyRequestColumns = "id, user_id, type, status, requested_by, handled_by, note, created_at, completed_at"
+
+func (repo *userRepository) CreatePrivacyRequest(ctx context.Context, request *entity.PrivacyRequest) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO privacy_requests (user_id, type, status, requested_by, note) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, request.UserID, request.Type, request.Status, request.RequestedBy, request.Note).Scan(&id)
+	if err != nil {
+		if isPqError(err, pqUniqueViolation) {
+			return "", user.ErrErasurePending
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (repo *userRepository) GetPrivacyRequestByID(ctx context.Context, id string) (*entity.PrivacyRequest, error) {
+	sqlQuery := "SELECT " + privacyRequestColumns + " FROM privacy_requests WHERE id = $1"
+	request, err := scanPrivacyRequest(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, notFound(err, user.ErrPrivacyRequestNotFound)
+	}
+
+	return request, nil
+}
+
+// GetPrivacyRequests lists requests newest first, an empty userID or status matches all
+func (repo *userRepository) GetPrivacyRequests(ctx context.Context, userID string, status entity.PrivacyRequestStatus) ([]*entity.PrivacyRequest, error) {
+	sqlQuery := "SELECT " + privacyRequestColumns + ` FROM privacy_requests
+		WHERE ($1 = '' OR user_id::text = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*entity.PrivacyRequest{}
+	for rows.Next() {
+		request, err := scanPrivacyRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
+
+// CompletePrivacyRequest moves a pending request to its final status
+func (repo *userRepository) CompletePrivacyRequest(ctx context.Context, id, handledBy string, status entity.PrivacyRequestStatus, note string) error {
+	query := `UPDATE privacy_requests SET status = $2, handled_by = NULLIF($3, '')::uuid, note = $4, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+	result, err := repo.db.ExecContext(ctx, query, id, status, handledBy, note)
+	if err != nil {
+		return notFound(err, user.ErrPrivacyRequestNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrPrivacyRequestHandled
+	}
+
+	return nil
+}
+
+// EraseUser anonymizes the personal data of the user in place and completes the erasure request in one transaction.
+// The row itself stays so records that reference it keep their integrity. It returns the email that was erased.
+func (repo *userRepository) EraseUser(ctx context.Context, requestID, userID, handledBy string) (string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRowContext(ctx, "SELECT email FROM users WHERE id = $1 AND erased_at IS NULL FOR UPDATE", userID).Scan(&email)
+	if err != nil {
+		return "", notFound(err, user.ErrUserNotFound)
+	}
+
+	queries := []string{
+		`UPDATE users SET fullname = 'Erased user', email = 'erased+' || id || '@invalid', password = '',
+			date_of_birth = NULL, mfa_secret = '', mfa_enabled_at = NULL, mfa_last_step = NULL,
+			erased_at = NOW(), deleted_at = COALESCE(deleted_at, NOW()), updated_at = NOW()
+			WHERE id = $1`,
+		"UPDATE age_verifications SET document_reference = '', note = '' WHERE user_id = $1",
+		"UPDATE token_families SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL",
+		"DELETE FROM password_resets WHERE user_id = $1",
+		"DELETE FROM mfa_recovery_codes WHERE user_id = $1",
+		// orders stay for the books, only the address they were shipped to is personal data
+		"UPDATE orders SET shipping_address = NULL, updated_at = NOW() WHERE user_id = $1 AND shipping_address IS NOT NULL",
+		"DELETE FROM carts WHERE user_id = $1",
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return "", err
+		}
+	}
+
+	query := `UPDATE privacy_requests SET status = 'completed', handled_by = NULLIF($2, '')::uuid, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+	result, err := tx.ExecContext(ctx, query, requestID, handledBy)
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", user.ErrPrivacyRequestHandled
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return email, nil
+}
+
+// GetUserDataExport collects every row tied to the user, login attempts live behind their own repository
+func (repo *userRepository) GetUserDataExport(ctx context.Context, userID string) (*entity.DataExport, error) {
+	export := &entity.DataExport{
+		ExportedAt:       time.Now(),
+		AgeVerifications: []entity.DataExportAgeCheck{},
+		Sessions:         []entity.DataExportSession{},
+		PasswordResets:   []entity.DataExportReset{},
+		Changes:          []entity.DataExportChange{},
+		Orders:           []*entity.Order{},
+		Cart:             []entity.DataExportCartItem{},
+	}
+
+	p := &export.Profile
+	sqlQuery := `SELECT id, fullname, email, role, date_of_birth, jurisdiction, age_verification_status,
+		email_verified_at, mfa_enabled_at, created_at, updated_at
+		FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, userID).Scan(&p.ID, &p.FullName, &p.Email, &p.Role, &p.DateOfBirth, &p.Jurisdiction,
+		&p.AgeVerificationStatus, &p.EmailVerifiedAt, &p.MfaEnabledAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, user.ErrUserNotFound)
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT method, document_reference, status, note, created_at
+		FROM age_verifications WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		var check entity.DataExportAgeCheck
+		if err := rows.Scan(&check.Method, &check.DocumentReference, &check.Status, &check.Note, &check.CreatedAt); err != nil {
+			return err
+		}
+		export.AgeVerifications = append(export.AgeVerifications, check)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `SELECT created_at, revoked_at, mfa_authenticated
+		FROM token_families WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		var session entity.DataExportSession
+		if err := rows.Scan(&session.CreatedAt, &session.RevokedAt, &session.MfaAuthenticated); err != nil {
+			return err
+		}
+		export.Sessions = append(export.Sessions, session)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `SELECT created_at, expires_at, used_at
+		FROM password_resets WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		var reset entity.DataExportReset
+		if err := rows.Scan(&reset.CreatedAt, &reset.ExpiresAt, &reset.UsedAt); err != nil {
+			return err
+		}
+		export.PasswordResets = append(export.PasswordResets, reset)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `SELECT changed_by, fields, created_at
+		FROM user_changes WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		var change entity.DataExportChange
+		if err := rows.Scan(&change.ChangedBy, pq.Array(&change.Fields), &change.CreatedAt); err != nil {
+			return err
+		}
+		export.Changes = append(export.Changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.exportOrders(ctx, export, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `SELECT ci.variant_id, v.sku, p.name, ci.quantity, ci.created_at
+		FROM carts c
+		JOIN cart_items ci ON ci.cart_id = c.id
+		JOIN product_variants v ON v.id = ci.variant_id
+		JOIN products p ON p.id = v.product_id
+		WHERE c.user_id = $1 ORDER BY ci.created_at, v.sku`, userID)
+	if err != nil {
+		return nil, err
+	}
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		var item entity.DataExportCartItem
+		if err := rows.Scan(&item.VariantID, &item.SKU, &item.ProductName, &item.Quantity, &item.AddedAt); err != nil {
+			return err
+		}
+		export.Cart = append(export.Cart, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export.PrivacyRequests, err = repo.GetPrivacyRequests(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// exportOrders adds the orders of the user with the items as they were ordered
+func (repo *userRepository) exportOrders(ctx context.Context, export *entity.DataExport, userID string) error {
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, user_id, status, fulfilment_method, location_id, shipping_address,
+			subtotal, discount, shipping_fee, tax, total, created_at, updated_at
+		FROM orders WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*entity.Order)
+	err = scanEach(rows, func(rows *sql.Rows) error {
+		o := &entity.Order{Items: []*entity.OrderItem{}}
+		var encodedAddress []byte
+		err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.FulfilmentMethod, &o.LocationID, &encodedAddress,
+			&o.Subtotal, &o.Discount, &o.ShippingFee, &o.Tax, &o.Total, &o.CreatedAt, &o.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if len(encodedAddress) > 0 {
+			if err := json.Unmarshal(encodedAddress, &o.ShippingAddress); err != nil {
+				return err
+			}
+		}
+		byID[o.ID] = o
+		export.Orders = append(export.Orders, o)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `SELECT oi.order_id, oi.variant_id, oi.product_id, oi.product_name, oi.sku, oi.options,
+			oi.quantity, oi.unit_price, oi.line_total
+		FROM order_items oi JOIN orders o ON o.id = oi.order_id
+		WHERE o.user_id = $1 ORDER BY oi.product_name, oi.sku`, userID)
+	if err != nil {
+		return err
+	}
+	return scanEach(rows, func(rows *sql.Rows) error {
+		var orderID string
+		var encodedOptions []byte
+		item := new(entity.OrderItem)
+		err := rows.Scan(&orderID, &item.VariantID, &item.ProductID, &item.ProductName, &item.SKU, &encodedOptions,
+			&item.Quantity, &item.UnitPrice, &item.LineTotal)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(encodedOptions, &item.Options); err != nil {
+			return err
+		}
+		if o, ok := byID[orderID]; ok {
+			o.Items = append(o.Items, item)
+		}
+		return nil
+	})
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPrivacyRequest(row rowScanner) (*entity.PrivacyRequest, error) {
+	request := new(entity.PrivacyRequest)
+	err := row.Scan(&request.ID, &request.UserID, &request.Type, &request.Status, &request.RequestedBy,
+		&request.HandledBy, &request.Note, &request.CreatedAt, &request.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// scanEach calls scan for every row and closes rows afterwards
+func scanEach(rows *sql.Rows, scan func(rows *sql.Rows) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
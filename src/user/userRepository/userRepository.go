@@ -4,9 +4,14 @@ import (
 	"clean-architecture/model/dto/userDto"
 	"clean-architecture/model/entity"
 	"clean-architecture/src/user"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type userRepository struct {
@@ -17,113 +22,261 @@ func NewUserRepository(db *sql.DB) user.UserRepository {
 	return &userRepository{db}
 }
 
-func (repo *userRepository) CreateUser(user *userDto.CreateUserRequest) error {
-	sqlQuery := `INSERT INTO users (email,fullname, password) VALUES ($1, $2,$3)`
-	_, err := repo.db.Exec(sqlQuery, user.Email, user.FullName, user.Password)
+func (repo *userRepository) CreateUser(ctx context.Context, newUser *userDto.CreateUserRequest) (string, error) {
+	var id string
+	sqlQuery := `INSERT INTO users (email,fullname, password, date_of_birth, jurisdiction) VALUES ($1, $2,$3, $4, $5) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, newUser.Email, newUser.FullName, newUser.Password, newUser.DateOfBirth, newUser.Jurisdiction).Scan(&id)
 	if err != nil {
-		return err
+		if isPqError(err, pqUniqueViolation) {
+			return "", user.ErrEmailInUse
+		}
+		return "", err
 	}
 
-	return nil
+	return id, nil
 }
 
-func (repo *userRepository) GetUserByEmail(email string) (*entity.User, error) {
-	sqlQuery := `SELECT id, email, fullname, password FROM users WHERE email = $1`
-	row := repo.db.QueryRow(sqlQuery, email)
+func (repo *userRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	sqlQuery := `SELECT id, email, fullname, password, role, date_of_birth, jurisdiction, age_verification_status, email_verified_at, mfa_secret, mfa_enabled_at, created_at FROM users WHERE email = $1 AND deleted_at IS NULL`
+	row := repo.db.QueryRowContext(ctx, sqlQuery, email)
 	u := new(entity.User)
-	err := row.Scan(&u.ID, &u.Email, &u.FullName, &u.Password)
+	err := row.Scan(&u.ID, &u.Email, &u.FullName, &u.Password, &u.Role, &u.DateOfBirth, &u.Jurisdiction, &u.AgeVerificationStatus, &u.EmailVerifiedAt, &u.MfaSecret, &u.MfaEnabledAt, &u.CreatedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, err
-		}
-		return nil, err
+		return nil, notFound(err, user.ErrUserNotFound)
 	}
 	return u, nil
 }
 
-func (repo *userRepository) GetUserByID(id string) (*entity.User, error) {
-	sqlQuery := `SELECT id, email, email, password FROM users WHERE id = $1`
-	rows, err := repo.db.Query(sqlQuery, id)
+func (repo *userRepository) GetUserByID(ctx context.Context, id string) (*entity.User, error) {
+	sqlQuery := `SELECT id, fullname, email, password, role, date_of_birth, jurisdiction, age_verification_status, email_verified_at, mfa_secret, mfa_enabled_at, created_at FROM users WHERE id = $1 AND deleted_at IS NULL`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err, user.ErrUserNotFound)
 	}
-	u := new(entity.User)
-	for rows.Next() {
-		u, err = scanRowsIntoUser(rows)
-		if err != nil {
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
+		return nil, user.ErrUserNotFound
 	}
-	return u, nil
+	return scanRowsIntoUser(rows)
 }
 
-func (repo *userRepository) GetUsers(page, limit int, email, fullName string) ([]*entity.User, int, error) {
-	offset := (page - 1) * limit
-	baseQuery := "SELECT id, fullname, email, password FROM users WHERE deleted_at IS NULL"
+// userSortColumns maps the allow-listed sort fields to their columns, nothing else reaches ORDER BY
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"fullname":   "fullname",
+	"email":      "email",
+}
 
-	var conditions []string
+// GetUsers returns up to query.Size+1 users so the caller can tell whether another page follows,
+// together with the number of users matching the filters
+func (repo *userRepository) GetUsers(ctx context.Context, query *userDto.UserListQuery, after *userDto.UserCursor) ([]*entity.User, int, error) {
+	sortColumn, ok := userSortColumns[query.SortBy]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort field %q", query.SortBy)
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
 	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	if email != "" {
-		conditions = append(conditions, "email = $1")
-		args = append(args, email)
-	}
-	if fullName != "" {
-		if email != "" {
-			conditions = append(conditions, "fullname = $2")
-			args = append(args, fullName)
-		} else {
-			conditions = append(conditions, "fullname = $1")
-			args = append(args, fullName)
-		}
+	if query.Email != "" {
+		conditions = append(conditions, "email ILIKE "+arg(containsPattern(query.Email))+` ESCAPE '\'`)
+	}
+	if query.FullName != "" {
+		conditions = append(conditions, "fullname ILIKE "+arg(containsPattern(query.FullName))+` ESCAPE '\'`)
+	}
+	if query.Role != "" {
+		conditions = append(conditions, "role = "+arg(query.Role))
+	}
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*query.CreatedFrom))
+	}
+	if query.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*query.CreatedTo))
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	count := 0
+	err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+where, args...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if len(conditions) > 0 {
-		baseQuery += " AND " + strings.Join(conditions, " AND ")
+	direction, comparison := "ASC", ">"
+	if query.Descending {
+		direction, comparison = "DESC", "<"
 	}
 
-	orderLimitOffset := fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
-	baseQuery += orderLimitOffset
+	pageWhere := where
+	if after != nil {
+		cursorValue := arg(after.Value)
+		if sortColumn == "created_at" {
+			cursorValue += "::timestamptz"
+		}
+		pageWhere += fmt.Sprintf(" AND (%s, id) %s (%s, %s::uuid)", sortColumn, comparison, cursorValue, arg(after.ID))
+	}
 
-	args = append(args, limit, offset)
+	sqlQuery := "SELECT id, fullname, email, password, role, date_of_birth, jurisdiction, age_verification_status, email_verified_at, mfa_secret, mfa_enabled_at, created_at FROM users" +
+		pageWhere + fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", sortColumn, direction, direction, arg(query.Size+1))
+	if after == nil {
+		sqlQuery += " OFFSET " + arg((query.Page-1)*query.Size)
+	}
 
-	rows, err := repo.db.Query(baseQuery, args...)
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []*entity.User
+	users := []*entity.User{}
 	for rows.Next() {
-		user := new(entity.User)
-		if err := rows.Scan(&user.ID, &user.FullName, &user.Email, &user.Password); err != nil {
+		user, err := scanRowsIntoUser(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		users = append(users, user)
 	}
-
-	count, err := GetTotalUsers(repo.db)
-	if err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
 
 	return users, count, nil
 }
 
-func (repo *userRepository) UpdateUser(user *userDto.UpdateUserRequest) error {
-	query := "UPDATE users SET fullname = $2, password = $3 WHERE id = $1"
-	_, err := repo.db.Exec(query, user.ID, user.FullName, user.Password)
+// containsPattern turns user input into an ILIKE pattern matching it anywhere, wildcards in the input are literal
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return "%" + escaped + "%"
+}
+
+// PatchUser updates only the columns present in the patch and records which fields changed.
+// A password change also revokes every session of the user.
+func (repo *userRepository) PatchUser(ctx context.Context, id, changedBy string, patch *userDto.PatchUserRequest) error {
+	var setClauses, fields []string
+	args := []interface{}{id}
+
+	if patch.FullName != nil {
+		args = append(args, *patch.FullName)
+		setClauses = append(setClauses, fmt.Sprintf("fullname = $%d", len(args)))
+		fields = append(fields, "fullname")
+	}
+	if patch.Password != nil {
+		args = append(args, *patch.Password)
+		setClauses = append(setClauses, fmt.Sprintf("password = $%d", len(args)))
+		fields = append(fields, "password")
+	}
+	if len(setClauses) == 0 {
+		return nil
+	}
+	setClauses = append(setClauses, "updated_at = NOW()")
+
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	query := "UPDATE users SET " + strings.Join(setClauses, ", ") + " WHERE id = $1 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return notFound(err, user.ErrUserNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	query = "INSERT INTO user_changes (user_id, changed_by, fields) VALUES ($1, NULLIF($2, '')::uuid, $3)"
+	_, err = tx.ExecContext(ctx, query, id, changedBy, pq.Array(fields))
+	if err != nil {
+		return err
+	}
+
+	if patch.Password != nil {
+		query = "UPDATE token_families SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+		_, err = tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (repo *userRepository) DeleteUser(id string) error {
+// UpdatePassword stores the new password and revokes every session of the user except keepFamilyID atomically
+func (repo *userRepository) UpdatePassword(ctx context.Context, id, hashedPassword, keepFamilyID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE users SET password = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, id, hashedPassword)
+	if err != nil {
+		return notFound(err, user.ErrUserNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	query = `UPDATE token_families SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2`
+	_, err = tx.ExecContext(ctx, query, id, keepFamilyID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *userRepository) UpdateUserRole(ctx context.Context, id string, role entity.Role) error {
+	query := "UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	return execAffectingUser(ctx, repo.db, query, id, role)
+}
+
+func (repo *userRepository) DeleteUser(ctx context.Context, id string) error {
 	query := "UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	return execAffectingUser(ctx, repo.db, query, id)
+}
+
+// execAffectingUser runs a statement on one user row and reports ErrUserNotFound when it matched nothing
+func execAffectingUser(ctx context.Context, db *sql.DB, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return notFound(err, user.ErrUserNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return nil
+}
 
-	_, err := repo.db.Exec(query, id)
+func (repo *userRepository) RevokeUserTokenFamilies(ctx context.Context, userID string) error {
+	query := "UPDATE token_families SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err := repo.db.ExecContext(ctx, query, userID)
 	if err != nil {
 		return err
 	}
@@ -131,15 +284,131 @@ func (repo *userRepository) DeleteUser(id string) error {
 	return nil
 }
 
-func GetTotalUsers(db *sql.DB) (int, error) {
+func (repo *userRepository) GetDeletedUsers(ctx context.Context, page, limit int) ([]*entity.User, int, error) {
+	offset := (page - 1) * limit
+	sqlQuery := `SELECT id, fullname, email, role, deleted_at FROM users
+		WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT $1 OFFSET $2`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*entity.User
+	for rows.Next() {
+		user := new(entity.User)
+		if err := rows.Scan(&user.ID, &user.FullName, &user.Email, &user.Role, &user.DeletedAt); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
 	count := 0
-	sqlQuery := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
-	row := db.QueryRow(sqlQuery)
-	err := row.Scan(&count)
+	err = repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE deleted_at IS NOT NULL`).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, count, nil
+}
+
+// RestoreUser undeletes a user, it fails with ErrEmailInUse when the email was registered again meanwhile
+func (repo *userRepository) RestoreUser(ctx context.Context, id string) error {
+	query := "UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at IS NOT NULL AND erased_at IS NULL"
+	err := execAffectingUser(ctx, repo.db, query, id)
+	if isPqError(err, pqUniqueViolation) {
+		return user.ErrEmailInUse
+	}
+	return err
+}
+
+// PurgeUser permanently removes a soft-deleted user together with its credentials and sessions.
+// It returns the email of the purged user.
+func (repo *userRepository) PurgeUser(ctx context.Context, id string) (string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var deleted bool
+	var email string
+	err = tx.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL, email FROM users WHERE id = $1 FOR UPDATE", id).Scan(&deleted, &email)
 	if err != nil {
-		return 0, err
+		return "", notFound(err, user.ErrUserNotFound)
+	}
+	if !deleted {
+		return "", user.ErrUserNotDeleted
+	}
+
+	queries := []string{
+		"DELETE FROM token_families WHERE user_id = $1",
+		"DELETE FROM password_resets WHERE user_id = $1",
+		"DELETE FROM mfa_recovery_codes WHERE user_id = $1",
+		"DELETE FROM age_verifications WHERE user_id = $1",
+		"UPDATE age_verifications SET reviewer_id = NULL WHERE reviewer_id = $1",
+		"DELETE FROM users WHERE id = $1",
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			if isPqError(err, pqForeignKeyViolation) {
+				return "", user.ErrPurgeBlocked
+			}
+			return "", err
+		}
 	}
-	return count, nil
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return email, nil
+}
+
+// GetPurgeableUserIDs leaves out erased users, whose anonymized row is kept, and users who placed orders,
+// the orders are kept for the books and hold on to the user row
+func (repo *userRepository) GetPurgeableUserIDs(ctx context.Context, deletedBefore time.Time, limit int) ([]string, error) {
+	sqlQuery := `SELECT id FROM users WHERE deleted_at < $1 AND erased_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)
+		ORDER BY deleted_at LIMIT $2`
+	rows, err := repo.db.QueryContext(ctx, sqlQuery, deletedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqForeignKeyViolation       = "23503"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
 }
 
 func scanRowsIntoUser(rows *sql.Rows) (*entity.User, error) {
@@ -150,6 +419,14 @@ func scanRowsIntoUser(rows *sql.Rows) (*entity.User, error) {
 		&user.FullName,
 		&user.Email,
 		&user.Password,
+		&user.Role,
+		&user.DateOfBirth,
+		&user.Jurisdiction,
+		&user.AgeVerificationStatus,
+		&user.EmailVerifiedAt,
+		&user.MfaSecret,
+		&user.MfaEnabledAt,
+		&user.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -157,3 +434,317 @@ func scanRowsIntoUser(rows *sql.Rows) (*entity.User, error) {
 
 	return user, nil
 }
+
+func (repo *userRepository) CreateTokenFamily(ctx context.Context, userID string, mfaAuthenticated bool) (string, error) {
+	var familyID string
+	sqlQuery := `INSERT INTO token_families (user_id, mfa_authenticated) VALUES ($1, $2) RETURNING id`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, userID, mfaAuthenticated).Scan(&familyID)
+	if err != nil {
+		return "", err
+	}
+
+	return familyID, nil
+}
+
+func (repo *userRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	query := "UPDATE token_families SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+	_, err := repo.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *userRepository) IsTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	var revokedAt sql.NullTime
+	sqlQuery := `SELECT revoked_at FROM token_families WHERE id = $1`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, familyID).Scan(&revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return revokedAt.Valid, nil
+}
+
+func (repo *userRepository) CreateRefreshToken(ctx context.Context, familyID, tokenHash string, expiresAt time.Time) error {
+	sqlQuery := `INSERT INTO refresh_tokens (family_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := repo.db.ExecContext(ctx, sqlQuery, familyID, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *userRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	sqlQuery := `SELECT rt.id, rt.family_id, tf.user_id, rt.token_hash, rt.expires_at, rt.used_at, tf.mfa_authenticated
+		FROM refresh_tokens rt
+		JOIN token_families tf ON tf.id = rt.family_id
+		WHERE rt.token_hash = $1`
+	row := repo.db.QueryRowContext(ctx, sqlQuery, tokenHash)
+
+	token := new(entity.RefreshToken)
+	var usedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.FamilyID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &usedAt, &token.MfaAuthenticated)
+	if err != nil {
+		return nil, notFound(err, user.ErrInvalidRefreshToken)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
+
+// MarkRefreshTokenUsed flags a refresh token as consumed, reporting false when it was already used
+func (repo *userRepository) MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error) {
+	query := "UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL"
+	result, err := repo.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CreateAgeVerification records a verification event and moves the user to its status in one transaction
+func (repo *userRepository) CreateAgeVerification(ctx context.Context, verification *entity.AgeVerification) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sqlQuery := `INSERT INTO age_verifications (user_id, method, document_reference, status, reviewer_id, note)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6)`
+	_, err = tx.ExecContext(ctx, sqlQuery, verification.UserID, verification.Method, verification.DocumentReference,
+		verification.Status, verification.ReviewerID, verification.Note)
+	if err != nil {
+		if isPqError(err, pqForeignKeyViolation) {
+			return user.ErrUserNotFound
+		}
+		return notFound(err, user.ErrUserNotFound)
+	}
+
+	query := "UPDATE users SET age_verification_status = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, verification.UserID, verification.Status)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return user.ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (repo *userRepository) GetAgeVerificationStatus(ctx context.Context, userID string) (entity.AgeVerificationStatus, error) {
+	var status entity.AgeVerificationStatus
+	sqlQuery := `SELECT age_verification_status FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := repo.db.QueryRowContext(ctx, sqlQuery, userID).Scan(&status)
+	if err != nil {
+		return "", notFound(err, user.ErrUserNotFound)
+	}
+
+	return status, nil
+}
+
+// MarkEmailVerified verifies the address only while it is still the one the token was issued for
+func (repo *userRepository) MarkEmailVerified(ctx context.Context, id, email string) (bool, error) {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL`
+	result, err := repo.db.ExecContext(ctx, query, id, email)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CreatePasswordReset stores a new reset token and retires any the user still had outstanding
+func (repo *userRepository) CreatePasswordReset(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL"
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err = tx.ExecContext(ctx, sqlQuery, userID, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword consumes the reset token, stores the new password and revokes every session of the user atomically
+func (repo *userRepository) ResetPassword(ctx context.Context, tokenHash, hashedPassword string) (string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	query := `UPDATE password_resets SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+	err = tx.QueryRowContext(ctx, query, tokenHash).Scan(&userID)
+	if err != nil {
+		return "", notFound(err, user.ErrInvalidResetToken)
+	}
+
+	query = "UPDATE users SET password = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	result, err := tx.ExecContext(ctx, query, userID, hashedPassword)
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", user.ErrInvalidResetToken
+	}
+
+	query = "UPDATE token_families SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// SetMfaSecret stores a pending totp secret, an already enabled secret is never replaced
+func (repo *userRepository) SetMfaSecret(ctx context.Context, userID, secret string) (bool, error) {
+	query := `UPDATE users SET mfa_secret = $2, mfa_last_step = NULL, updated_at = NOW()
+		WHERE id = $1 AND mfa_enabled_at IS NULL AND deleted_at IS NULL`
+	result, err := repo.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// EnableMfa activates the pending secret and replaces the recovery codes in one transaction
+func (repo *userRepository) EnableMfa(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE users SET mfa_enabled_at = NOW(), updated_at = NOW() WHERE id = $1 AND mfa_secret <> ''"
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, sqlQuery, userID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (repo *userRepository) DisableMfa(ctx context.Context, userID string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET mfa_secret = '', mfa_enabled_at = NULL, mfa_last_step = NULL, updated_at = NOW()
+		WHERE id = $1`
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseMfaStep accepts a totp step only when it is newer than the last accepted one
+func (repo *userRepository) UseMfaStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE users SET mfa_last_step = $2
+		WHERE id = $1 AND (mfa_last_step IS NULL OR mfa_last_step < $2)`
+	result, err := repo.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (repo *userRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	result, err := repo.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
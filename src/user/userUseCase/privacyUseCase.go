@@ -0,0 +1,157 @@
+package userUseCase
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/user"
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ExportUserData collects everything stored about the user and logs the export as a privacy request
+func (useCase *UserUC) ExportUserData(ctx context.Context, actor entity.Actor, id string) (*entity.DataExport, error) {
+	if !actor.CanManage(id, entity.PermissionPrivacyManage) {
+		return nil, user.ErrForbidden
+	}
+
+	// only users that exist get a request logged against them
+	if _, err := useCase.userRepo.GetUserByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	requestID, err := useCase.userRepo.CreatePrivacyRequest(ctx, &entity.PrivacyRequest{
+		UserID:      id,
+		Type:        entity.PrivacyRequestExport,
+		Status:      entity.PrivacyRequestPending,
+		RequestedBy: actor.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := useCase.collectUserData(ctx, id)
+	if err != nil {
+		if errComplete := useCase.userRepo.CompletePrivacyRequest(ctx, requestID, "", entity.PrivacyRequestFailed, "export failed"); errComplete != nil {
+			log.Error().Msg("ExportUserData.CompletePrivacyRequest.err : " + errComplete.Error())
+		}
+		return nil, err
+	}
+
+	if err := useCase.userRepo.CompletePrivacyRequest(ctx, requestID, "", entity.PrivacyRequestCompleted, ""); err != nil {
+		return nil, err
+	}
+	for i := range export.PrivacyRequests {
+		if export.PrivacyRequests[i].ID == requestID {
+			export.PrivacyRequests[i].Status = entity.PrivacyRequestCompleted
+		}
+	}
+
+	return export, nil
+}
+
+func (useCase *UserUC) collectUserData(ctx context.Context, id string) (*entity.DataExport, error) {
+	export, err := useCase.userRepo.GetUserDataExport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := useCase.loginAttemptRepo.GetLoginAttempt(ctx, loginAccountKey(export.Profile.Email))
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Failures > 0 || attempt.LockedUntil != nil {
+		export.LoginAttempts = &entity.DataExportLoginRecord{
+			Failures:      attempt.Failures,
+			LastFailureAt: attempt.LastFailureAt,
+			LockedUntil:   attempt.LockedUntil,
+		}
+	}
+
+	return export, nil
+}
+
+// RequestErasure queues an erasure of the caller's own account for review, the password is checked again first
+func (useCase *UserUC) RequestErasure(ctx context.Context, userID, password string) (*entity.PrivacyRequest, error) {
+	existingUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !useCase.ComparePasswords(existingUser.Password, []byte(password)) {
+		return nil, user.ErrInvalidCurrentPassword
+	}
+
+	requestID, err := useCase.userRepo.CreatePrivacyRequest(ctx, &entity.PrivacyRequest{
+		UserID:      userID,
+		Type:        entity.PrivacyRequestErasure,
+		Status:      entity.PrivacyRequestPending,
+		RequestedBy: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.userRepo.GetPrivacyRequestByID(ctx, requestID)
+}
+
+func (useCase *UserUC) GetPrivacyRequests(ctx context.Context, userID string, status entity.PrivacyRequestStatus) ([]*entity.PrivacyRequest, error) {
+	return useCase.userRepo.GetPrivacyRequests(ctx, userID, status)
+}
+
+// ApproveErasure anonymizes the user of a pending erasure request and signs them out everywhere
+func (useCase *UserUC) ApproveErasure(ctx context.Context, actor entity.Actor, requestID string) error {
+	if !actor.Can(entity.PermissionPrivacyManage) {
+		return user.ErrForbidden
+	}
+
+	request, err := useCase.pendingPrivacyRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if request.Type != entity.PrivacyRequestErasure {
+		return user.ErrPrivacyRequestHandled
+	}
+
+	email, err := useCase.userRepo.EraseUser(ctx, requestID, request.UserID, actor.ID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			// the user was erased through an earlier request
+			if err := useCase.userRepo.CompletePrivacyRequest(ctx, requestID, actor.ID, entity.PrivacyRequestFailed, "user already erased"); err != nil {
+				return err
+			}
+			return user.ErrUserAlreadyErased
+		}
+		return err
+	}
+
+	if err := useCase.loginAttemptRepo.ResetLoginAttempts(ctx, loginAccountKey(email)); err != nil {
+		log.Error().Msg("ApproveErasure.ResetLoginAttempts.err : " + err.Error())
+	}
+
+	return nil
+}
+
+func (useCase *UserUC) RejectPrivacyRequest(ctx context.Context, actor entity.Actor, requestID, note string) error {
+	if !actor.Can(entity.PermissionPrivacyManage) {
+		return user.ErrForbidden
+	}
+
+	if _, err := useCase.pendingPrivacyRequest(ctx, requestID); err != nil {
+		return err
+	}
+
+	return useCase.userRepo.CompletePrivacyRequest(ctx, requestID, actor.ID, entity.PrivacyRequestRejected, note)
+}
+
+func (useCase *UserUC) pendingPrivacyRequest(ctx context.Context, requestID string) (*entity.PrivacyRequest, error) {
+	request, err := useCase.userRepo.GetPrivacyRequestByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != entity.PrivacyRequestPending {
+		return nil, user.ErrPrivacyRequestHandled
+	}
+
+	return request, nil
+}
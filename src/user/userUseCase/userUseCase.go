@@ -1,28 +1,320 @@
 package userUseCase
 
 import (
+	"clean-architecture/model/dto"
 	"clean-architecture/model/dto/userDto"
 	"clean-architecture/model/entity"
+	"clean-architecture/pkg/mailer"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/totp"
 	"clean-architecture/src/user"
+	"clean-architecture/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 	"unicode"
 
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUC struct {
-	userRepo user.UserRepository
+	userRepo         user.UserRepository
+	loginAttemptRepo user.LoginAttemptRepository
+	mail             mailer.Mailer
+	config           dto.ConfigData
 }
 
-func NewUserUseCase(userRepo user.UserRepository) user.UserUseCase {
-	return &UserUC{userRepo}
+// dummyPasswordHash is compared against when the email is unknown so both failure paths cost one bcrypt round
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+func NewUserUseCase(userRepo user.UserRepository, loginAttemptRepo user.LoginAttemptRepository, mail mailer.Mailer, config dto.ConfigData) user.UserUseCase {
+	return &UserUC{userRepo, loginAttemptRepo, mail, config}
+}
+
+func (useCase *UserUC) CreateUser(ctx context.Context, newUser *userDto.CreateUserRequest) error {
+	if newUser.Jurisdiction == "" {
+		newUser.Jurisdiction = useCase.config.AgeConfig.DefaultJurisdiction
+	}
+	newUser.Jurisdiction = strings.ToUpper(newUser.Jurisdiction)
+
+	legalAge, ok := useCase.config.AgeConfig.LegalAges[newUser.Jurisdiction]
+	if !ok {
+		return user.ErrUnsupportedJurisdiction
+	}
+
+	dateOfBirth, err := time.Parse(time.DateOnly, newUser.DateOfBirth)
+	if err != nil {
+		return err
+	}
+
+	if !isOfLegalAge(dateOfBirth, legalAge, time.Now()) {
+		return user.ErrUnderLegalAge
+	}
+
+	if !useCase.IsValidPassword(newUser.Password) {
+		return user.ErrWeakPassword
+	}
+
+	hashedPassword, err := useCase.HashPassword(newUser.Password)
+	if err != nil {
+		return err
+	}
+
+	// a taken email surfaces as ErrEmailInUse from the unique index
+	id, err := useCase.userRepo.CreateUser(ctx, &userDto.CreateUserRequest{
+		Email:        newUser.Email,
+		FullName:     newUser.FullName,
+		Password:     hashedPassword,
+		DateOfBirth:  newUser.DateOfBirth,
+		Jurisdiction: newUser.Jurisdiction,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the account exists at this point, a mail failure is recoverable through the resend endpoint
+	if err := useCase.sendVerificationEmail(ctx, id, newUser.Email); err != nil {
+		log.Error().Msg("CreateUser.sendVerificationEmail.err : " + err.Error())
+	}
+
+	return nil
+}
+
+// SendVerificationEmail resends the verification link, unknown or already verified addresses are silently ignored
+func (useCase *UserUC) SendVerificationEmail(ctx context.Context, email string) error {
+	existingUser, err := useCase.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existingUser.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return useCase.sendVerificationEmail(ctx, existingUser.ID, existingUser.Email)
+}
+
+func (useCase *UserUC) sendVerificationEmail(ctx context.Context, userID, email string) error {
+	verificationConfig := useCase.config.EmailVerificationConfig
+	token, err := middleware.GeneratePurposeToken(userID, email, entity.TokenPurposeEmailVerification, verificationConfig.TokenLifeTime)
+	if err != nil {
+		return err
+	}
+
+	link := token
+	if verificationConfig.VerifyURL != "" {
+		link = verificationConfig.VerifyURL + "?token=" + url.QueryEscape(token)
+	}
+
+	return useCase.mail.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Please confirm your email address by opening the link below.\n\n%s\n\nThe link expires in %s.\n",
+			link, verificationConfig.TokenLifeTime),
+	})
+}
+
+func (useCase *UserUC) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := middleware.ParsePurposeToken(token, entity.TokenPurposeEmailVerification)
+	if err != nil {
+		return user.ErrInvalidVerificationToken
+	}
+
+	verified, err := useCase.userRepo.MarkEmailVerified(ctx, claims.ID, claims.Subject)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return user.ErrInvalidVerificationToken
+	}
+
+	return nil
 }
 
-func (useCase *UserUC) CreateUser(user *userDto.CreateUserRequest) error {
-	return useCase.userRepo.CreateUser(user)
+// Login checks credentials while counting failures per account and per client ip.
+// Every credential failure returns ErrInvalidCredentials so callers cannot tell unknown emails apart.
+func (useCase *UserUC) Login(ctx context.Context, email, password, clientIP string) (*entity.User, error) {
+	accountKey := loginAccountKey(email)
+	ipKey := "ip:" + clientIP
+
+	if err := useCase.checkLoginLocks(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	loginUser, err := useCase.userRepo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if loginUser == nil {
+		useCase.ComparePasswords(string(dummyPasswordHash), []byte(password))
+		return nil, useCase.recordLoginFailure(ctx, accountKey, ipKey, user.ErrInvalidCredentials)
+	}
+
+	if !useCase.ComparePasswords(loginUser.Password, []byte(password)) {
+		return nil, useCase.recordLoginFailure(ctx, accountKey, ipKey, user.ErrInvalidCredentials)
+	}
+
+	// the ip counter is left alone so one valid account cannot clear it for a stuffing run
+	if err := useCase.loginAttemptRepo.ResetLoginAttempts(ctx, accountKey); err != nil {
+		return nil, err
+	}
+
+	return loginUser, nil
+}
+
+func (useCase *UserUC) checkLoginLocks(ctx context.Context, keys ...string) error {
+	now := time.Now()
+	for _, key := range keys {
+		attempt, err := useCase.loginAttemptRepo.GetLoginAttempt(ctx, key)
+		if err != nil {
+			return err
+		}
+		if attempt.IsLocked(now) {
+			return user.ErrLoginLocked
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts the failure, locks keys past their limit and slows the response down progressively before returning failErr
+func (useCase *UserUC) recordLoginFailure(ctx context.Context, accountKey, ipKey string, failErr error) error {
+	loginConfig := useCase.config.LoginProtectionConfig
+	limits := map[string]int{
+		accountKey: loginConfig.MaxAccountFailures,
+		ipKey:      loginConfig.MaxIPFailures,
+	}
+
+	maxFailures := 0
+	for key, limit := range limits {
+		failures, err := useCase.loginAttemptRepo.RecordLoginFailure(ctx, key, loginConfig.FailureWindow)
+		if err != nil {
+			return err
+		}
+
+		if failures >= limit {
+			err := useCase.loginAttemptRepo.LockLogin(ctx, key, time.Now().Add(loginConfig.LockDuration))
+			if err != nil {
+				return err
+			}
+		}
+
+		if failures > maxFailures {
+			maxFailures = failures
+		}
+	}
+
+	delay := loginConfig.BaseDelay << (maxFailures - 1)
+	if delay > loginConfig.MaxDelay || delay <= 0 {
+		delay = loginConfig.MaxDelay
+	}
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return failErr
+}
+
+// UnlockLogin clears the failure counter and lock of an account
+func (useCase *UserUC) UnlockLogin(ctx context.Context, actor entity.Actor, id string) error {
+	if !actor.Can(entity.PermissionUserWrite) {
+		return user.ErrForbidden
+	}
+
+	lockedUser, err := useCase.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return useCase.loginAttemptRepo.ResetLoginAttempts(ctx, loginAccountKey(lockedUser.Email))
+}
+
+func loginAccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// CheckLoginAllowed applies the login policies that come after the password check
+func (useCase *UserUC) CheckLoginAllowed(loginUser *entity.User) error {
+	if useCase.config.EmailVerificationConfig.RequiredForLogin && loginUser.EmailVerifiedAt == nil {
+		return user.ErrEmailNotVerified
+	}
+
+	return nil
 }
 
-func (useCase *UserUC) GetUserByEmail(email string) (*entity.User, error) {
-	return useCase.userRepo.GetUserByEmail(email)
+// isOfLegalAge reports whether someone born at dateOfBirth has had their legalAge birthday by now
+func isOfLegalAge(dateOfBirth time.Time, legalAge int, now time.Time) bool {
+	return !dateOfBirth.AddDate(legalAge, 0, 0).After(now)
+}
+
+// SubmitAgeDocument puts the customer in the document review queue
+func (useCase *UserUC) SubmitAgeDocument(ctx context.Context, userID, documentReference string) error {
+	status, err := useCase.userRepo.GetAgeVerificationStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status == entity.AgeVerificationVerified {
+		return user.ErrAgeAlreadyVerified
+	}
+
+	return useCase.userRepo.CreateAgeVerification(ctx, &entity.AgeVerification{
+		UserID:            userID,
+		Method:            entity.AgeVerificationMethodDocument,
+		DocumentReference: documentReference,
+		Status:            entity.AgeVerificationDocumentReview,
+	})
+}
+
+// ReviewAgeVerification records a staff decision, either after checking a document or an in-store ID check
+func (useCase *UserUC) ReviewAgeVerification(ctx context.Context, actor entity.Actor, userID string, status entity.AgeVerificationStatus, note string) error {
+	if !actor.Can(entity.PermissionAgeVerify) {
+		return user.ErrForbidden
+	}
+
+	currentStatus, err := useCase.userRepo.GetAgeVerificationStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	method := entity.AgeVerificationMethodManual
+	if currentStatus == entity.AgeVerificationDocumentReview {
+		method = entity.AgeVerificationMethodDocument
+	}
+
+	return useCase.userRepo.CreateAgeVerification(ctx, &entity.AgeVerification{
+		UserID:     userID,
+		Method:     method,
+		Status:     status,
+		ReviewerID: actor.ID,
+		Note:       note,
+	})
+}
+
+func (useCase *UserUC) IsAgeVerified(ctx context.Context, userID string) (bool, error) {
+	status, err := useCase.userRepo.GetAgeVerificationStatus(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return status == entity.AgeVerificationVerified, nil
+}
+
+func (useCase *UserUC) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return useCase.userRepo.GetUserByEmail(ctx, email)
 }
 
 func (useCase *UserUC) ComparePasswords(hashed string, plain []byte) bool {
@@ -60,18 +352,599 @@ func (useCase *UserUC) IsValidPassword(password string) bool {
 	return hasUpper && hasLower && hasDigit && hasSpecial
 }
 
-func (useCase *UserUC) GetUsers(page, limit int, email, fullName string) ([]*entity.User, int, error) {
-	return useCase.userRepo.GetUsers(page, limit, email, fullName)
+// GetUsers pages through the filtered users either by offset or after an opaque cursor
+func (useCase *UserUC) GetUsers(ctx context.Context, query *userDto.UserListQuery) (*userDto.UserPage, error) {
+	var after *userDto.UserCursor
+	if query.Cursor != "" {
+		cursor, err := decodeUserCursor(query.Cursor)
+		if err != nil || cursor.SortBy != query.SortBy || cursor.Descending != query.Descending {
+			return nil, user.ErrInvalidCursor
+		}
+		after = cursor
+	}
+
+	users, total, err := useCase.userRepo.GetUsers(ctx, query, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &userDto.UserPage{Users: users, Total: total}
+	if len(users) > query.Size {
+		page.Users = users[:query.Size]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor, err = encodeUserCursor(&userDto.UserCursor{
+			SortBy:     query.SortBy,
+			Descending: query.Descending,
+			Value:      userSortValue(last, query.SortBy),
+			ID:         last.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func userSortValue(u *entity.User, sortBy string) string {
+	switch sortBy {
+	case "fullname":
+		return u.FullName
+	case "email":
+		return u.Email
+	default:
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func encodeUserCursor(cursor *userDto.UserCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeUserCursor(encoded string) (*userDto.UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := new(userDto.UserCursor)
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, err
+	}
+	// the values reach typed sql parameters, reject anything that would fail there instead of in the database
+	if !isUUID(cursor.ID) {
+		return nil, user.ErrInvalidCursor
+	}
+	if cursor.SortBy == "created_at" {
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return nil, user.ErrInvalidCursor
+		}
+	}
+	return cursor, nil
+}
+
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i, char := range value {
+		switch i {
+		case 8, 13, 18, 23:
+			if char != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", char) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (useCase *UserUC) GetUserByID(ctx context.Context, actor entity.Actor, id string) (*entity.User, error) {
+	if !actor.CanManage(id, entity.PermissionUserRead) {
+		return nil, user.ErrForbidden
+	}
+
+	return useCase.userRepo.GetUserByID(ctx, id)
+}
+
+// UpdateUser replaces both fields, it is the PUT form of PatchUser
+func (useCase *UserUC) UpdateUser(ctx context.Context, actor entity.Actor, updatedUser *userDto.UpdateUserRequest) error {
+	return useCase.PatchUser(ctx, actor, updatedUser.ID, &userDto.PatchUserRequest{
+		FullName: &updatedUser.FullName,
+		Password: &updatedUser.Password,
+	})
+}
+
+func (useCase *UserUC) PatchUser(ctx context.Context, actor entity.Actor, id string, patch *userDto.PatchUserRequest) error {
+	if !actor.CanManage(id, entity.PermissionUserWrite) {
+		return user.ErrForbidden
+	}
+	if patch.FullName == nil && patch.Password == nil {
+		return user.ErrEmptyPatch
+	}
+	if actor.ID != id {
+		// users:write alone would let a store manager take over an admin or fellow staff account
+		target, err := useCase.userRepo.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !actor.Role.Outranks(target.Role) || (target.Role.IsStaff() && !actor.Can(entity.PermissionRoleAssign)) {
+			return user.ErrForbidden
+		}
+	}
+
+	changes := &userDto.PatchUserRequest{FullName: patch.FullName}
+	if patch.Password != nil {
+		// changing your own password always goes through ChangePassword, which checks the current one
+		if actor.ID == id || !actor.Can(entity.PermissionUserWrite) {
+			return user.ErrPasswordNeedsCurrent
+		}
+		if !useCase.IsValidPassword(*patch.Password) {
+			return user.ErrWeakPassword
+		}
+
+		hashedPassword, err := useCase.HashPassword(*patch.Password)
+		if err != nil {
+			return err
+		}
+		changes.Password = &hashedPassword
+	}
+
+	return useCase.userRepo.PatchUser(ctx, id, actor.ID, changes)
+}
+
+// ChangePassword requires the current password and signs out every other session of the user
+func (useCase *UserUC) ChangePassword(ctx context.Context, userID, familyID, currentPassword, newPassword string) error {
+	existingUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !useCase.ComparePasswords(existingUser.Password, []byte(currentPassword)) {
+		return user.ErrInvalidCurrentPassword
+	}
+
+	if !useCase.IsValidPassword(newPassword) {
+		return user.ErrWeakPassword
+	}
+
+	hashedPassword, err := useCase.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return useCase.userRepo.UpdatePassword(ctx, userID, hashedPassword, familyID)
+}
+
+func (useCase *UserUC) UpdateUserRole(ctx context.Context, actor entity.Actor, id string, role entity.Role) error {
+	if actor.ID == id {
+		return user.ErrSelfRoleChange
+	}
+	if !actor.Can(entity.PermissionRoleAssign) {
+		return user.ErrForbidden
+	}
+
+	return useCase.userRepo.UpdateUserRole(ctx, id, role)
+}
+
+func (useCase *UserUC) DeleteUser(ctx context.Context, actor entity.Actor, id string) error {
+	// self deletion goes through DeleteOwnAccount so it always re-checks the password
+	if actor.ID == id {
+		return user.ErrSelfDeleteNeedsPassword
+	}
+	if !actor.Can(entity.PermissionUserDelete) {
+		return user.ErrForbidden
+	}
+
+	return useCase.deleteUser(ctx, id)
+}
+
+func (useCase *UserUC) DeleteOwnAccount(ctx context.Context, userID, password string) error {
+	existingUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !useCase.ComparePasswords(existingUser.Password, []byte(password)) {
+		return user.ErrInvalidCurrentPassword
+	}
+
+	return useCase.deleteUser(ctx, userID)
+}
+
+func (useCase *UserUC) GetDeletedUsers(ctx context.Context, page, limit int) ([]*entity.User, int, error) {
+	return useCase.userRepo.GetDeletedUsers(ctx, page, limit)
+}
+
+func (useCase *UserUC) RestoreUser(ctx context.Context, actor entity.Actor, id string) error {
+	if !actor.Can(entity.PermissionUserDelete) {
+		return user.ErrForbidden
+	}
+
+	return useCase.userRepo.RestoreUser(ctx, id)
+}
+
+func (useCase *UserUC) PurgeUser(ctx context.Context, actor entity.Actor, id string) error {
+	if !actor.Can(entity.PermissionUserDelete) {
+		return user.ErrForbidden
+	}
+
+	return useCase.purgeUser(ctx, id)
+}
+
+// purgeUser removes the user and the login failures counted against its email, the last place the email is kept
+func (useCase *UserUC) purgeUser(ctx context.Context, id string) error {
+	email, err := useCase.userRepo.PurgeUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := useCase.loginAttemptRepo.ResetLoginAttempts(ctx, loginAccountKey(email)); err != nil {
+		log.Error().Msg("purgeUser.ResetLoginAttempts.err : " + err.Error())
+	}
+
+	return nil
+}
+
+// purgeBatchSize bounds how many users one purge run removes so a backlog cannot hold the job for long
+const purgeBatchSize = 100
+
+// PurgeExpiredUsers permanently removes users deleted longer ago than the retention period.
+// Users still referenced by other records are skipped and retried on the next run.
+func (useCase *UserUC) PurgeExpiredUsers(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().Add(-useCase.config.RetentionConfig.DeletedUserRetention)
+	ids, err := useCase.userRepo.GetPurgeableUserIDs(ctx, deletedBefore, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := useCase.purgeUser(ctx, id); err != nil {
+			if errors.Is(err, user.ErrPurgeBlocked) {
+				log.Warn().Msg("PurgeExpiredUsers.PurgeUser : " + id + " " + err.Error())
+				continue
+			}
+			return purged, err
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		log.Info().Msg(fmt.Sprintf("PurgeExpiredUsers : purged %d user(s)", purged))
+	}
+	return purged, nil
+}
+
+func (useCase *UserUC) deleteUser(ctx context.Context, id string) error {
+	if err := useCase.userRepo.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+
+	return useCase.userRepo.RevokeUserTokenFamilies(ctx, id)
+}
+
+// IssueTokens starts a new token family for the user and returns its first access and refresh token pair
+func (useCase *UserUC) IssueTokens(ctx context.Context, user *entity.User, mfaAuthenticated bool) (*userDto.TokenResponse, error) {
+	familyID, err := useCase.userRepo.CreateTokenFamily(ctx, user.ID, mfaAuthenticated)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.issueTokenPair(ctx, user, familyID, mfaAuthenticated)
 }
 
-func (useCase *UserUC) GetUserByID(id string) (*entity.User, error) {
-	return useCase.userRepo.GetUserByID(id)
+// RefreshTokens rotates a refresh token; presenting an already used token revokes its whole family
+func (useCase *UserUC) RefreshTokens(ctx context.Context, refreshToken string) (*userDto.TokenResponse, error) {
+	token, err := useCase.userRepo.GetRefreshTokenByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := useCase.userRepo.IsTokenFamilyRevoked(ctx, token.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked || time.Now().After(token.ExpiresAt) {
+		return nil, user.ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		if err := useCase.userRepo.RevokeTokenFamily(ctx, token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, user.ErrRefreshTokenReused
+	}
+
+	marked, err := useCase.userRepo.MarkRefreshTokenUsed(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		// lost a race against another request presenting the same token
+		if err := useCase.userRepo.RevokeTokenFamily(ctx, token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, user.ErrRefreshTokenReused
+	}
+
+	// reload the user so role changes apply on the next rotation
+	tokenUser, err := useCase.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.issueTokenPair(ctx, tokenUser, token.FamilyID, token.MfaAuthenticated)
 }
 
-func (useCase *UserUC) UpdateUser(user *userDto.UpdateUserRequest) error {
-	return useCase.userRepo.UpdateUser(user)
+func (useCase *UserUC) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	return useCase.userRepo.RevokeTokenFamily(ctx, familyID)
+}
+
+func (useCase *UserUC) IsTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	return useCase.userRepo.IsTokenFamilyRevoked(ctx, familyID)
+}
+
+func (useCase *UserUC) issueTokenPair(ctx context.Context, user *entity.User, familyID string, mfaAuthenticated bool) (*userDto.TokenResponse, error) {
+	mfaRequired := useCase.roleRequiresMfa(user.Role) && !mfaAuthenticated
+	accessToken, err := middleware.GenerateTokenJwt(user.ID, user.Role, familyID, mfaRequired, useCase.config.JwtConfig.AccessTokenLifeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(useCase.config.JwtConfig.RefreshTokenLifeTime)
+	err = useCase.userRepo.CreateRefreshToken(ctx, familyID, utils.HashToken(refreshToken), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userDto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(useCase.config.JwtConfig.AccessTokenLifeTime.Seconds()),
+	}, nil
+}
+
+// ForgotPassword emails a single use reset link, unknown addresses are silently ignored
+func (useCase *UserUC) ForgotPassword(ctx context.Context, email string) error {
+	existingUser, err := useCase.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetConfig := useCase.config.PasswordResetConfig
+	expiresAt := time.Now().Add(resetConfig.TokenLifeTime)
+	err = useCase.userRepo.CreatePasswordReset(ctx, existingUser.ID, utils.HashToken(token), expiresAt)
+	if err != nil {
+		return err
+	}
+
+	link := token
+	if resetConfig.ResetURL != "" {
+		link = resetConfig.ResetURL + "?token=" + url.QueryEscape(token)
+	}
+
+	return useCase.mail.Send(ctx, mailer.Message{
+		To:      existingUser.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("A password reset was requested for your account. Open the link below to choose a new password.\n\n%s\n\nThe link expires in %s and works once. If you did not ask for this, ignore this email.\n",
+			link, resetConfig.TokenLifeTime),
+	})
+}
+
+// ResetPassword sets a new password from a reset token and signs the user out everywhere
+func (useCase *UserUC) ResetPassword(ctx context.Context, token, password string) error {
+	if !useCase.IsValidPassword(password) {
+		return user.ErrWeakPassword
+	}
+
+	hashedPassword, err := useCase.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = useCase.userRepo.ResetPassword(ctx, utils.HashToken(token), hashedPassword)
+	return err
+}
+
+func (useCase *UserUC) roleRequiresMfa(role entity.Role) bool {
+	for _, required := range useCase.config.MfaConfig.RequiredRoles {
+		if entity.Role(required) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueMfaChallenge returns the short lived token that stands between the password step and the totp step
+func (useCase *UserUC) IssueMfaChallenge(loginUser *entity.User) (*userDto.MfaChallengeResponse, error) {
+	lifeTime := useCase.config.MfaConfig.PendingTokenLifeTime
+	token, err := middleware.GeneratePurposeToken(loginUser.ID, "", entity.TokenPurposeMfaPending, lifeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userDto.MfaChallengeResponse{
+		MfaRequired: true,
+		MfaToken:    token,
+		ExpiresIn:   int64(lifeTime.Seconds()),
+	}, nil
+}
+
+// LoginMfa completes a two step login with a totp or recovery code, failures count towards the login lockout.
+// Every failure of the challenge or the code is reported as ErrInvalidMfaLogin.
+func (useCase *UserUC) LoginMfa(ctx context.Context, mfaToken, code, clientIP string) (*entity.User, error) {
+	claims, err := middleware.ParsePurposeToken(mfaToken, entity.TokenPurposeMfaPending)
+	if err != nil {
+		return nil, user.ErrInvalidMfaLogin
+	}
+
+	loginUser, err := useCase.userRepo.GetUserByID(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, user.ErrInvalidMfaLogin
+		}
+		return nil, err
+	}
+
+	accountKey := loginAccountKey(loginUser.Email)
+	ipKey := "ip:" + clientIP
+	if err := useCase.checkLoginLocks(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	valid, err := useCase.verifyMfaCode(ctx, loginUser, code)
+	if err != nil && !errors.Is(err, user.ErrMfaNotEnrolled) {
+		return nil, err
+	}
+	if !valid {
+		return nil, useCase.recordLoginFailure(ctx, accountKey, ipKey, user.ErrInvalidMfaLogin)
+	}
+
+	if err := useCase.loginAttemptRepo.ResetLoginAttempts(ctx, accountKey); err != nil {
+		return nil, err
+	}
+
+	return loginUser, nil
+}
+
+// verifyMfaCode accepts either a fresh totp code or an unused recovery code
+func (useCase *UserUC) verifyMfaCode(ctx context.Context, mfaUser *entity.User, code string) (bool, error) {
+	if mfaUser.MfaSecret == "" {
+		return false, user.ErrMfaNotEnrolled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(mfaUser.MfaSecret, code, time.Now(), 1); ok {
+		return useCase.userRepo.UseMfaStep(ctx, mfaUser.ID, step)
+	}
+
+	if mfaUser.MfaEnabledAt == nil {
+		return false, nil
+	}
+
+	return useCase.userRepo.UseRecoveryCode(ctx, mfaUser.ID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// EnrollMfa creates a pending totp secret, it only becomes active once ConfirmMfa sees a valid code
+func (useCase *UserUC) EnrollMfa(ctx context.Context, userID string) (*userDto.MfaEnrollResponse, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	mfaUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := useCase.userRepo.SetMfaSecret(ctx, userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, user.ErrMfaAlreadyEnabled
+	}
+
+	return &userDto.MfaEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(useCase.config.MfaConfig.Issuer, mfaUser.Email, secret),
+	}, nil
+}
+
+// ConfirmMfa enables totp after the first valid code and returns recovery codes, they are only ever shown here
+func (useCase *UserUC) ConfirmMfa(ctx context.Context, userID, code string) (*userDto.MfaRecoveryCodesResponse, error) {
+	mfaUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaUser.MfaEnabledAt != nil {
+		return nil, user.ErrMfaAlreadyEnabled
+	}
+
+	valid, err := useCase.verifyMfaCode(ctx, mfaUser, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, user.ErrInvalidMfaCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = utils.HashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	if err := useCase.userRepo.EnableMfa(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &userDto.MfaRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMfa turns totp off after proving possession, roles under the mandatory policy cannot opt out
+func (useCase *UserUC) DisableMfa(ctx context.Context, userID, code string) error {
+	mfaUser, err := useCase.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mfaUser.MfaEnabledAt == nil {
+		return user.ErrMfaNotEnrolled
+	}
+	if useCase.roleRequiresMfa(mfaUser.Role) {
+		return user.ErrMfaRequiredForRole
+	}
+
+	valid, err := useCase.verifyMfaCode(ctx, mfaUser, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return user.ErrInvalidMfaCode
+	}
+
+	return useCase.userRepo.DisableMfa(ctx, userID)
+}
+
+const recoveryCodeCount = 10
+
+// generateRecoveryCode returns a code shaped like abcde-fghij
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
 }
 
-func (useCase *UserUC) DeleteUser(id string) error {
-	return useCase.userRepo.DeleteUser(id)
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
 }
@@ -0,0 +1,378 @@
+package userUseCase
+
+import (
+	"clean-architecture/model/dto"
+	"clean-architecture/model/dto/userDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/user"
+	"clean-architecture/src/user/userRepository"
+	"clean-architecture/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// stubUserRepository serves users and refresh tokens from maps and records changes, every other method panics
+type stubUserRepository struct {
+	user.UserRepository
+	users   map[string]*entity.User
+	patched []string
+
+	// refreshTokens are keyed by token hash, lostMarkRace makes MarkRefreshTokenUsed report another request won
+	refreshTokens   map[string]*entity.RefreshToken
+	revokedFamilies map[string]bool
+	lostMarkRace    bool
+}
+
+func (repo *stubUserRepository) GetUserByID(_ context.Context, id string) (*entity.User, error) {
+	existingUser, ok := repo.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return existingUser, nil
+}
+
+func (repo *stubUserRepository) GetUserByEmail(_ context.Context, email string) (*entity.User, error) {
+	for _, existingUser := range repo.users {
+		if existingUser.Email == email {
+			return existingUser, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (repo *stubUserRepository) GetRefreshTokenByHash(_ context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	token, ok := repo.refreshTokens[tokenHash]
+	if !ok {
+		return nil, user.ErrInvalidRefreshToken
+	}
+	return token, nil
+}
+
+func (repo *stubUserRepository) IsTokenFamilyRevoked(_ context.Context, familyID string) (bool, error) {
+	return repo.revokedFamilies[familyID], nil
+}
+
+func (repo *stubUserRepository) RevokeTokenFamily(_ context.Context, familyID string) error {
+	repo.revokedFamilies[familyID] = true
+	return nil
+}
+
+func (repo *stubUserRepository) MarkRefreshTokenUsed(_ context.Context, id string) (bool, error) {
+	return !repo.lostMarkRace, nil
+}
+
+func (repo *stubUserRepository) PatchUser(_ context.Context, id, _ string, _ *userDto.PatchUserRequest) error {
+	repo.patched = append(repo.patched, id)
+	return nil
+}
+
+func TestPatchUserGuardsAccountsOfEqualOrHigherRank(t *testing.T) {
+	users := map[string]*entity.User{
+		"customer": {ID: "customer", Role: entity.RoleCustomer},
+		"cashier":  {ID: "cashier", Role: entity.RoleCashier},
+		"manager":  {ID: "manager", Role: entity.RoleStoreManager},
+		"admin":    {ID: "admin", Role: entity.RoleAdmin},
+	}
+	name := "New Name"
+	password := "N3w-Passw0rd!"
+
+	tests := []struct {
+		name    string
+		actor   entity.Actor
+		target  string
+		patch   *userDto.PatchUserRequest
+		wantErr error
+	}{
+		{
+			name:    "store manager sets the password of an admin",
+			actor:   entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target:  "admin",
+			patch:   &userDto.PatchUserRequest{Password: &password},
+			wantErr: user.ErrForbidden,
+		},
+		{
+			name:    "store manager renames an admin",
+			actor:   entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target:  "admin",
+			patch:   &userDto.PatchUserRequest{FullName: &name},
+			wantErr: user.ErrForbidden,
+		},
+		{
+			name:    "store manager sets the password of another store manager",
+			actor:   entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target:  "manager",
+			patch:   &userDto.PatchUserRequest{Password: &password},
+			wantErr: user.ErrForbidden,
+		},
+		{
+			name:    "store manager sets the password of a cashier",
+			actor:   entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target:  "cashier",
+			patch:   &userDto.PatchUserRequest{Password: &password},
+			wantErr: user.ErrForbidden,
+		},
+		{
+			name:   "store manager sets the password of a customer",
+			actor:  entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target: "customer",
+			patch:  &userDto.PatchUserRequest{Password: &password},
+		},
+		{
+			name:    "admin renames another admin",
+			actor:   entity.Actor{ID: "admin-2", Role: entity.RoleAdmin},
+			target:  "admin",
+			patch:   &userDto.PatchUserRequest{FullName: &name},
+			wantErr: user.ErrForbidden,
+		},
+		{
+			name:   "admin sets the password of a store manager",
+			actor:  entity.Actor{ID: "admin-2", Role: entity.RoleAdmin},
+			target: "manager",
+			patch:  &userDto.PatchUserRequest{Password: &password},
+		},
+		{
+			name:   "admin renames themselves",
+			actor:  entity.Actor{ID: "admin", Role: entity.RoleAdmin},
+			target: "admin",
+			patch:  &userDto.PatchUserRequest{FullName: &name},
+		},
+		{
+			name:    "store manager patches an unknown user",
+			actor:   entity.Actor{ID: "manager-2", Role: entity.RoleStoreManager},
+			target:  "missing",
+			patch:   &userDto.PatchUserRequest{FullName: &name},
+			wantErr: user.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubUserRepository{users: users}
+			useCase := &UserUC{userRepo: repo}
+
+			err := useCase.PatchUser(context.Background(), tt.actor, tt.target, tt.patch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PatchUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if patched := len(repo.patched) > 0; patched != (tt.wantErr == nil) {
+				t.Errorf("PatchUser() patched = %v, want %v", patched, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestRefreshTokensRevokesFamilyOnReuse(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		token        entity.RefreshToken
+		revoked      bool
+		lostMarkRace bool
+		wantErr      error
+		wantRevoked  bool
+	}{
+		{
+			name:        "token presented a second time",
+			token:       entity.RefreshToken{ExpiresAt: now.Add(time.Hour), UsedAt: &past},
+			wantErr:     user.ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:         "token used by a concurrent request",
+			token:        entity.RefreshToken{ExpiresAt: now.Add(time.Hour)},
+			lostMarkRace: true,
+			wantErr:      user.ErrRefreshTokenReused,
+			wantRevoked:  true,
+		},
+		{
+			name:        "token of a revoked family",
+			token:       entity.RefreshToken{ExpiresAt: now.Add(time.Hour)},
+			revoked:     true,
+			wantErr:     user.ErrInvalidRefreshToken,
+			wantRevoked: true,
+		},
+		{
+			name:        "expired token",
+			token:       entity.RefreshToken{ExpiresAt: past},
+			wantErr:     user.ErrInvalidRefreshToken,
+			wantRevoked: false,
+		},
+		{
+			name:        "expired token presented a second time",
+			token:       entity.RefreshToken{ExpiresAt: past, UsedAt: &past},
+			wantErr:     user.ErrInvalidRefreshToken,
+			wantRevoked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			token.ID, token.FamilyID, token.UserID = "token", "family", "user"
+			repo := &stubUserRepository{
+				refreshTokens:   map[string]*entity.RefreshToken{utils.HashToken("refresh"): &token},
+				revokedFamilies: map[string]bool{"family": tt.revoked},
+				lostMarkRace:    tt.lostMarkRace,
+			}
+			useCase := &UserUC{userRepo: repo}
+
+			_, err := useCase.RefreshTokens(context.Background(), "refresh")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshTokens() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.revokedFamilies["family"] != tt.wantRevoked {
+				t.Errorf("family revoked = %v, want %v", repo.revokedFamilies["family"], tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRefreshTokensRejectsUnknownToken(t *testing.T) {
+	repo := &stubUserRepository{refreshTokens: map[string]*entity.RefreshToken{}, revokedFamilies: map[string]bool{}}
+	useCase := &UserUC{userRepo: repo}
+
+	if _, err := useCase.RefreshTokens(context.Background(), "unknown"); !errors.Is(err, user.ErrInvalidRefreshToken) {
+		t.Fatalf("RefreshTokens() error = %v, want %v", err, user.ErrInvalidRefreshToken)
+	}
+}
+
+func TestLoginThrottlesAndLocksOut(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Passw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := map[string]*entity.User{
+		"alice": {ID: "alice", Email: "alice@example.com", Password: string(hash)},
+		"bob":   {ID: "bob", Email: "bob@example.com", Password: string(hash)},
+	}
+
+	type attempt struct {
+		email    string
+		password string
+		ip       string
+		wantErr  error
+	}
+	wrong := func(email, ip string) attempt {
+		return attempt{email, "wrong", ip, user.ErrInvalidCredentials}
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "failures below the account limit",
+			attempts: []attempt{
+				wrong("alice@example.com", "10.0.0.1"),
+				wrong("alice@example.com", "10.0.0.1"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.1", nil},
+			},
+		},
+		{
+			name: "a successful login clears the account counter",
+			attempts: []attempt{
+				wrong("alice@example.com", "10.0.0.1"),
+				wrong("alice@example.com", "10.0.0.2"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.3", nil},
+				wrong("alice@example.com", "10.0.0.4"),
+				wrong("alice@example.com", "10.0.0.5"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.6", nil},
+			},
+		},
+		{
+			name: "the account locks at its limit whatever the ip",
+			attempts: []attempt{
+				wrong("alice@example.com", "10.0.0.1"),
+				wrong("alice@example.com", "10.0.0.2"),
+				wrong("alice@example.com", "10.0.0.3"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.4", user.ErrLoginLocked},
+				{"bob@example.com", "Passw0rd!", "10.0.0.4", nil},
+			},
+		},
+		{
+			name: "unknown emails count against the account key",
+			attempts: []attempt{
+				wrong("nobody@example.com", "10.0.0.1"),
+				wrong("nobody@example.com", "10.0.0.2"),
+				wrong("nobody@example.com", "10.0.0.3"),
+				{"nobody@example.com", "wrong", "10.0.0.4", user.ErrLoginLocked},
+			},
+		},
+		{
+			name: "the ip locks at its limit across accounts",
+			attempts: []attempt{
+				wrong("alice@example.com", "10.0.0.1"),
+				wrong("bob@example.com", "10.0.0.1"),
+				wrong("carol@example.com", "10.0.0.1"),
+				wrong("dave@example.com", "10.0.0.1"),
+				wrong("erin@example.com", "10.0.0.1"),
+				{"bob@example.com", "Passw0rd!", "10.0.0.1", user.ErrLoginLocked},
+				{"bob@example.com", "Passw0rd!", "10.0.0.2", nil},
+			},
+		},
+		{
+			name: "a successful login leaves the ip counter alone",
+			attempts: []attempt{
+				wrong("carol@example.com", "10.0.0.1"),
+				wrong("dave@example.com", "10.0.0.1"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.1", nil},
+				wrong("erin@example.com", "10.0.0.1"),
+				wrong("frank@example.com", "10.0.0.1"),
+				wrong("grace@example.com", "10.0.0.1"),
+				{"alice@example.com", "Passw0rd!", "10.0.0.1", user.ErrLoginLocked},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := &UserUC{
+				userRepo:         &stubUserRepository{users: users},
+				loginAttemptRepo: userRepository.NewLoginAttemptMemoryRepository(),
+				config: dto.ConfigData{LoginProtectionConfig: dto.LoginProtectionConfig{
+					MaxAccountFailures: 3,
+					MaxIPFailures:      5,
+					FailureWindow:      time.Minute,
+					LockDuration:       time.Minute,
+					BaseDelay:          time.Microsecond,
+					MaxDelay:           time.Millisecond,
+				}},
+			}
+
+			for i, a := range tt.attempts {
+				_, err := useCase.Login(context.Background(), a.email, a.password, a.ip)
+				if !errors.Is(err, a.wantErr) {
+					t.Fatalf("attempt %d: Login(%s, %s) error = %v, want %v", i+1, a.email, a.ip, err, a.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginGivesUpWaitingWhenTheRequestEnds(t *testing.T) {
+	useCase := &UserUC{
+		userRepo:         &stubUserRepository{users: map[string]*entity.User{}},
+		loginAttemptRepo: userRepository.NewLoginAttemptMemoryRepository(),
+		config: dto.ConfigData{LoginProtectionConfig: dto.LoginProtectionConfig{
+			MaxAccountFailures: 3,
+			MaxIPFailures:      5,
+			FailureWindow:      time.Minute,
+			LockDuration:       time.Minute,
+			BaseDelay:          time.Hour,
+			MaxDelay:           time.Hour,
+		}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := useCase.Login(ctx, "nobody@example.com", "wrong", "10.0.0.1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Login() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
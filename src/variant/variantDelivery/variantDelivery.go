@@ -0,0 +1,124 @@
+package variantDelivery
+
+import (
+	"clean-architecture/model/dto/json"
+	"clean-architecture/model/dto/variantDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/pkg/middleware"
+	"clean-architecture/pkg/validation"
+	"clean-architecture/src/variant"
+
+	"github.com/gin-gonic/gin"
+)
+
+type variantDelivery struct {
+	variantUC variant.VariantUseCase
+}
+
+func NewVariantDelivery(v1Group *gin.RouterGroup, variantUC variant.VariantUseCase, jwtAuth gin.HandlerFunc) {
+	handler := variantDelivery{
+		variantUC: variantUC,
+	}
+
+	publicGroup := v1Group.Group("")
+	{
+		publicGroup.GET("/products/:slug/variants", handler.getActiveProductVariants)
+	}
+
+	adminGroup := v1Group.Group("", jwtAuth, middleware.RequirePermission(entity.PermissionCatalogManage))
+	{
+		adminGroup.PUT("/products/:id/options", handler.setProductOptions)
+		adminGroup.GET("/products/manage/:id/variants", handler.getProductVariants)
+		adminGroup.POST("/products/:id/variants", handler.createVariant)
+		adminGroup.POST("/products/:id/variants/generate", handler.generateVariants)
+		adminGroup.PUT("/variants/:id", handler.updateVariant)
+	}
+}
+
+func (c *variantDelivery) setProductOptions(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var optionsPayload *variantDto.SetOptionsRequest
+	if validationError := validation.BindJSON(ctx, &optionsPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "59", "01")
+		return
+	}
+
+	options, err := c.variantUC.SetProductOptions(ctx.Request.Context(), ID, optionsPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "59")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, options, "success", "59", "02")
+}
+
+func (c *variantDelivery) getProductVariants(ctx *gin.Context) {
+	variants, err := c.variantUC.GetProductVariants(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "60")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, variants, "success", "60", "01")
+}
+
+func (c *variantDelivery) getActiveProductVariants(ctx *gin.Context) {
+	variants, err := c.variantUC.GetActiveProductVariants(ctx.Request.Context(), ctx.Param("slug"))
+	if err != nil {
+		json.AbortWithError(ctx, err, "61")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, variants, "success", "61", "01")
+}
+
+func (c *variantDelivery) createVariant(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var variantPayload *variantDto.CreateVariantRequest
+	if validationError := validation.BindJSON(ctx, &variantPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "62", "01")
+		return
+	}
+
+	created, err := c.variantUC.CreateVariant(ctx.Request.Context(), ID, variantPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "62")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, created, "success", "62", "02")
+}
+
+func (c *variantDelivery) updateVariant(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var variantPayload *variantDto.UpdateVariantRequest
+	if validationError := validation.BindJSON(ctx, &variantPayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "63", "01")
+		return
+	}
+
+	updated, err := c.variantUC.UpdateVariant(ctx.Request.Context(), ID, variantPayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "63")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, updated, "success", "63", "02")
+}
+
+func (c *variantDelivery) generateVariants(ctx *gin.Context) {
+	ID := ctx.Param("id")
+	var generatePayload *variantDto.GenerateVariantsRequest
+	if validationError := validation.BindJSON(ctx, &generatePayload); len(validationError) > 0 {
+		json.NewResponseBadRequest(ctx, validationError, "bad request", "64", "01")
+		return
+	}
+
+	created, err := c.variantUC.GenerateVariants(ctx.Request.Context(), ID, generatePayload)
+	if err != nil {
+		json.AbortWithError(ctx, err, "64")
+		return
+	}
+
+	json.NewResponseSuccess(ctx, created, "success", "64", "02")
+}
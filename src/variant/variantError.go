@@ -0,0 +1,25 @@
+package variant
+
+import (
+	"clean-architecture/model/dto/variantDto"
+	"clean-architecture/pkg/domainError"
+	"fmt"
+)
+
+var (
+	ErrVariantNotFound = domainError.NotFound("variant not found")
+
+	ErrSKUInUse             = domainError.Conflict("sku already in use")
+	ErrBarcodeInUse         = domainError.Conflict("barcode already in use")
+	ErrDuplicateCombination = domainError.Conflict("a variant with these options already exists")
+	ErrOptionsInUse         = domainError.Conflict("existing variants do not fit the new options")
+
+	ErrInvalidOptions       = domainError.Validation("bad request", domainError.Field{Name: "options", Message: "must name one listed value for every option of the product"})
+	ErrDuplicateOptionValue = domainError.Validation("bad request", domainError.Field{Name: "options", Message: "option names and the values of an option must be unique"})
+	ErrMatrixTooLarge       = domainError.Validation("bad request", domainError.Field{Name: "options", Message: fmt.Sprintf("the variant matrix exceeds %d combinations", variantDto.MaxMatrixSize)})
+)
+
+// InvalidGeneratedSKU points at the input a generated SKU went wrong for, field is sku_prefix or options
+func InvalidGeneratedSKU(field, message string) error {
+	return domainError.Validation("bad request", domainError.Field{Name: field, Message: message})
+}
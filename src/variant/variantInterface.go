@@ -0,0 +1,25 @@
+package variant
+
+import (
+	"clean-architecture/model/dto/variantDto"
+	"clean-architecture/model/entity"
+	"context"
+)
+
+type VariantRepository interface {
+	GetProductOptions(ctx context.Context, productID string) ([]*entity.ProductOption, error)
+	SetProductOptions(ctx context.Context, productID string, options []*entity.ProductOption) error
+	GetVariants(ctx context.Context, productID string, activeOnly bool) ([]*entity.Variant, error)
+	GetVariantByID(ctx context.Context, id string) (*entity.Variant, error)
+	CreateVariants(ctx context.Context, productID string, variants []*entity.Variant) ([]string, error)
+	UpdateVariant(ctx context.Context, variant *entity.Variant) error
+}
+
+type VariantUseCase interface {
+	SetProductOptions(ctx context.Context, productID string, options *variantDto.SetOptionsRequest) ([]*entity.ProductOption, error)
+	GetProductVariants(ctx context.Context, productID string) (*variantDto.ProductVariantsResponse, error)
+	GetActiveProductVariants(ctx context.Context, slug string) (*variantDto.ProductVariantsResponse, error)
+	CreateVariant(ctx context.Context, productID string, variant *variantDto.CreateVariantRequest) (*entity.Variant, error)
+	UpdateVariant(ctx context.Context, id string, variant *variantDto.UpdateVariantRequest) (*entity.Variant, error)
+	GenerateVariants(ctx context.Context, productID string, request *variantDto.GenerateVariantsRequest) ([]*entity.Variant, error)
+}
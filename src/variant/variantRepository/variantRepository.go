@@ -0,0 +1,263 @@
+package variantRepository
+
+import (
+	"clean-architecture/model/entity"
+	"clean-architecture/src/product"
+	"clean-architecture/src/variant"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+type variantRepository struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) variant.VariantRepository {
+	return &variantRepository{db}
+}
+
+func (repo *variantRepository) GetProductOptions(ctx context.Context, productID string) ([]*entity.ProductOption, error) {
+	return getProductOptions(ctx, repo.db, productID)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func getProductOptions(ctx context.Context, db queryer, productID string) ([]*entity.ProductOption, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name, "values", position FROM product_options WHERE product_id = $1 ORDER BY position`, productID)
+	if err != nil {
+		return nil, notFound(err, product.ErrProductNotFound)
+	}
+	defer rows.Close()
+
+	options := []*entity.ProductOption{}
+	for rows.Next() {
+		option := new(entity.ProductOption)
+		if err := rows.Scan(&option.Name, pq.Array(&option.Values), &option.Position); err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	return options, rows.Err()
+}
+
+// SetProductOptions replaces the option axes, refusing when an existing variant would no longer fit them
+func (repo *variantRepository) SetProductOptions(ctx context.Context, productID string, options []*entity.ProductOption) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	variants, err := getVariants(ctx, tx, productID, false)
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if _, ok := entity.MatchOptions(options, v.Options); !ok {
+			return variant.ErrOptionsInUse
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_options WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for _, option := range options {
+		_, err := tx.ExecContext(ctx, `INSERT INTO product_options (product_id, name, position, "values") VALUES ($1, $2, $3, $4)`,
+			productID, option.Name, option.Position, pq.Array(option.Values))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (repo *variantRepository) GetVariants(ctx context.Context, productID string, activeOnly bool) ([]*entity.Variant, error) {
+	return getVariants(ctx, repo.db, productID, activeOnly)
+}
+
+func getVariants(ctx context.Context, db queryer, productID string, activeOnly bool) ([]*entity.Variant, error) {
+	sqlQuery := `SELECT id, product_id, sku, barcode, price, active, options, created_at, updated_at
+		FROM product_variants WHERE product_id = $1`
+	if activeOnly {
+		sqlQuery += " AND active"
+	}
+	sqlQuery += " ORDER BY created_at, sku"
+
+	rows, err := db.QueryContext(ctx, sqlQuery, productID)
+	if err != nil {
+		return nil, notFound(err, product.ErrProductNotFound)
+	}
+	defer rows.Close()
+
+	variants := []*entity.Variant{}
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+
+	return variants, rows.Err()
+}
+
+func (repo *variantRepository) GetVariantByID(ctx context.Context, id string) (*entity.Variant, error) {
+	sqlQuery := `SELECT id, product_id, sku, barcode, price, active, options, created_at, updated_at
+		FROM product_variants WHERE id = $1`
+	v, err := scanVariant(repo.db.QueryRowContext(ctx, sqlQuery, id))
+	if err != nil {
+		return nil, notFound(err, variant.ErrVariantNotFound)
+	}
+
+	return v, nil
+}
+
+// CreateVariants inserts all variants or none, the product row is locked so the options cannot change meanwhile
+func (repo *variantRepository) CreateVariants(ctx context.Context, productID string, variants []*entity.Variant) ([]string, error) {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	options, err := getProductOptions(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(variants))
+	for _, v := range variants {
+		matched, ok := entity.MatchOptions(options, v.Options)
+		if !ok {
+			return nil, variant.ErrInvalidOptions
+		}
+
+		encodedOptions, err := json.Marshal(matched)
+		if err != nil {
+			return nil, err
+		}
+
+		var id string
+		err = tx.QueryRowContext(ctx, `INSERT INTO product_variants (product_id, sku, barcode, price, active, options, option_key)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			productID, v.SKU, v.Barcode, v.Price, v.Active, encodedOptions, entity.OptionKey(matched)).Scan(&id)
+		if err != nil {
+			return nil, variantWriteError(err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (repo *variantRepository) UpdateVariant(ctx context.Context, v *entity.Variant) error {
+	result, err := repo.db.ExecContext(ctx, `UPDATE product_variants SET sku = $2, barcode = $3, price = $4, active = $5, updated_at = NOW()
+		WHERE id = $1`, v.ID, v.SKU, v.Barcode, v.Price, v.Active)
+	if err != nil {
+		return notFound(variantWriteError(err), variant.ErrVariantNotFound)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return variant.ErrVariantNotFound
+	}
+
+	return nil
+}
+
+func lockProduct(ctx context.Context, tx *sql.Tx, productID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&id)
+	if err != nil {
+		return notFound(err, product.ErrProductNotFound)
+	}
+	return nil
+}
+
+// variantWriteError maps the unique indexes of product_variants to their domain errors
+func variantWriteError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pqUniqueViolation {
+		return err
+	}
+
+	switch pqErr.Constraint {
+	case "product_variants_sku_key":
+		return variant.ErrSKUInUse
+	case "product_variants_barcode_key":
+		return variant.ErrBarcodeInUse
+	case "product_variants_option_key":
+		return variant.ErrDuplicateCombination
+	}
+	return err
+}
+
+const (
+	pqUniqueViolation           = "23505"
+	pqInvalidTextRepresentation = "22P02"
+)
+
+// notFound maps a missing row, or an id that is not a valid uuid, to the given domain error
+func notFound(err error, notFoundErr error) error {
+	if err == sql.ErrNoRows || isPqError(err, pqInvalidTextRepresentation) {
+		return notFoundErr
+	}
+	return err
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVariant(row rowScanner) (*entity.Variant, error) {
+	v := new(entity.Variant)
+	var encodedOptions []byte
+	err := row.Scan(
+		&v.ID,
+		&v.ProductID,
+		&v.SKU,
+		&v.Barcode,
+		&v.Price,
+		&v.Active,
+		&encodedOptions,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(encodedOptions, &v.Options); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
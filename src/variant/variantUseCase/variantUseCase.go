@@ -0,0 +1,289 @@
+package variantUseCase
+
+import (
+	"clean-architecture/model/dto/variantDto"
+	"clean-architecture/model/entity"
+	"clean-architecture/src/product"
+	"clean-architecture/src/variant"
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type VariantUC struct {
+	variantRepo variant.VariantRepository
+	productRepo product.ProductRepository
+}
+
+func NewVariantUseCase(variantRepo variant.VariantRepository, productRepo product.ProductRepository) variant.VariantUseCase {
+	return &VariantUC{variantRepo, productRepo}
+}
+
+func (useCase *VariantUC) SetProductOptions(ctx context.Context, productID string, request *variantDto.SetOptionsRequest) ([]*entity.ProductOption, error) {
+	options := make([]*entity.ProductOption, len(request.Options))
+	names := make(map[string]bool, len(request.Options))
+	for i, option := range request.Options {
+		name := strings.TrimSpace(option.Name)
+		if name == "" || names[strings.ToLower(name)] || hasDuplicateFold(option.Values) {
+			return nil, variant.ErrDuplicateOptionValue
+		}
+		names[strings.ToLower(name)] = true
+
+		values := make([]string, len(option.Values))
+		for j, value := range option.Values {
+			values[j] = strings.TrimSpace(value)
+		}
+		options[i] = &entity.ProductOption{Name: name, Values: values, Position: i}
+	}
+
+	if err := useCase.variantRepo.SetProductOptions(ctx, productID, options); err != nil {
+		return nil, err
+	}
+
+	return useCase.variantRepo.GetProductOptions(ctx, productID)
+}
+
+func hasDuplicateFold(values []string) bool {
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if key == "" || seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+// GetProductVariants returns the options and every variant, including inactive ones, of any product
+func (useCase *VariantUC) GetProductVariants(ctx context.Context, productID string) (*variantDto.ProductVariantsResponse, error) {
+	if _, err := useCase.productRepo.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return useCase.productVariants(ctx, productID, false)
+}
+
+// GetActiveProductVariants is the storefront view, only active variants of an active product
+func (useCase *VariantUC) GetActiveProductVariants(ctx context.Context, slug string) (*variantDto.ProductVariantsResponse, error) {
+	p, err := useCase.productRepo.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entity.ProductStatusActive {
+		return nil, product.ErrProductNotFound
+	}
+
+	return useCase.productVariants(ctx, p.ID, true)
+}
+
+func (useCase *VariantUC) productVariants(ctx context.Context, productID string, activeOnly bool) (*variantDto.ProductVariantsResponse, error) {
+	options, err := useCase.variantRepo.GetProductOptions(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := useCase.variantRepo.GetVariants(ctx, productID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return &variantDto.ProductVariantsResponse{Options: options, Variants: variants}, nil
+}
+
+func (useCase *VariantUC) CreateVariant(ctx context.Context, productID string, request *variantDto.CreateVariantRequest) (*entity.Variant, error) {
+	p, err := useCase.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	newVariant := &entity.Variant{
+		SKU:     strings.TrimSpace(request.SKU),
+		Barcode: request.Barcode,
+		Price:   p.Price,
+		Active:  true,
+		Options: request.Options,
+	}
+	if request.Price != nil {
+		newVariant.Price = *request.Price
+	}
+	if request.Active != nil {
+		newVariant.Active = *request.Active
+	}
+	if newVariant.Options == nil {
+		newVariant.Options = map[string]string{}
+	}
+
+	ids, err := useCase.variantRepo.CreateVariants(ctx, productID, []*entity.Variant{newVariant})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.variantRepo.GetVariantByID(ctx, ids[0])
+}
+
+func (useCase *VariantUC) UpdateVariant(ctx context.Context, id string, request *variantDto.UpdateVariantRequest) (*entity.Variant, error) {
+	err := useCase.variantRepo.UpdateVariant(ctx, &entity.Variant{
+		ID:      id,
+		SKU:     strings.TrimSpace(request.SKU),
+		Barcode: request.Barcode,
+		Price:   *request.Price,
+		Active:  *request.Active,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return useCase.variantRepo.GetVariantByID(ctx, id)
+}
+
+// GenerateVariants creates a variant for every option combination that has none yet and returns the new ones
+func (useCase *VariantUC) GenerateVariants(ctx context.Context, productID string, request *variantDto.GenerateVariantsRequest) ([]*entity.Variant, error) {
+	p, err := useCase.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := useCase.variantRepo.GetProductOptions(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 1
+	for _, option := range options {
+		size *= len(option.Values)
+		if size > variantDto.MaxMatrixSize {
+			return nil, variant.ErrMatrixTooLarge
+		}
+	}
+
+	if err := checkSKUParts(request.SKUPrefix, options); err != nil {
+		return nil, err
+	}
+
+	existing, err := useCase.variantRepo.GetVariants(ctx, productID, false)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(existing))
+	skuTaken := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		taken[entity.OptionKey(v.Options)] = true
+		skuTaken[v.SKU] = true
+	}
+
+	price := p.Price
+	if request.Price != nil {
+		price = *request.Price
+	}
+
+	var newVariants []*entity.Variant
+	for _, combination := range optionMatrix(options) {
+		if taken[entity.OptionKey(combination)] {
+			continue
+		}
+		sku := generateSKU(request.SKUPrefix, options, combination)
+		if len(sku) > variantDto.MaxSKULength {
+			return nil, variant.InvalidGeneratedSKU("options", fmt.Sprintf("generated SKU %s is longer than %d characters, shorten the prefix or the values of option %s",
+				sku, variantDto.MaxSKULength, longestPartOption(options, combination)))
+		}
+		if skuTaken[sku] {
+			return nil, variant.InvalidGeneratedSKU("sku_prefix", fmt.Sprintf("generated SKU %s is already used by a variant of this product", sku))
+		}
+		newVariants = append(newVariants, &entity.Variant{
+			SKU:     sku,
+			Price:   price,
+			Active:  request.Active,
+			Options: combination,
+		})
+	}
+	if len(newVariants) == 0 {
+		return []*entity.Variant{}, nil
+	}
+
+	ids, err := useCase.variantRepo.CreateVariants(ctx, productID, newVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*entity.Variant, len(ids))
+	for i, id := range ids {
+		if created[i], err = useCase.variantRepo.GetVariantByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
+
+// optionMatrix returns every combination of one value per option, in option order
+func optionMatrix(options []*entity.ProductOption) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, option := range options {
+		next := make([]map[string]string, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				extended := make(map[string]string, len(combination)+1)
+				for name, chosen := range combination {
+					extended[name] = chosen
+				}
+				extended[option.Name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// generateSKU joins the prefix and the option values in option order as upper case ascii words
+func generateSKU(prefix string, options []*entity.ProductOption, combination map[string]string) string {
+	parts := []string{skuPart(prefix)}
+	for _, option := range options {
+		parts = append(parts, skuPart(combination[option.Name]))
+	}
+	return strings.Join(parts, "-")
+}
+
+// longestPartOption names the option contributing the longest part to the SKU of a combination
+func longestPartOption(options []*entity.ProductOption, combination map[string]string) string {
+	longest, name := -1, ""
+	for _, option := range options {
+		if length := len(skuPart(combination[option.Name])); length > longest {
+			longest, name = length, option.Name
+		}
+	}
+	return name
+}
+
+// checkSKUParts makes sure every value gives a SKU part of its own, values such as "3 mg" and "3mg"
+// or values without any ascii letter or digit would otherwise produce clashing SKUs
+func checkSKUParts(prefix string, options []*entity.ProductOption) error {
+	if skuPart(prefix) == "" {
+		return variant.InvalidGeneratedSKU("sku_prefix", "must contain ascii letters or digits")
+	}
+
+	for _, option := range options {
+		valueOf := make(map[string]string, len(option.Values))
+		for _, value := range option.Values {
+			part := skuPart(value)
+			if part == "" {
+				return variant.InvalidGeneratedSKU("options", fmt.Sprintf("value %q of option %s has no ascii letters or digits for the SKU", value, option.Name))
+			}
+			if other, ok := valueOf[part]; ok {
+				return variant.InvalidGeneratedSKU("options", fmt.Sprintf("values %q and %q of option %s give the same SKU part %s", other, value, option.Name, part))
+			}
+			valueOf[part] = value
+		}
+	}
+
+	return nil
+}
+
+func skuPart(value string) string {
+	words := strings.FieldsFunc(strings.ToUpper(value), func(char rune) bool {
+		return char > unicode.MaxASCII || !(unicode.IsLetter(char) || unicode.IsDigit(char))
+	})
+	return strings.Join(words, "")
+}
@@ -0,0 +1,153 @@
+package variantUseCase
+
+import (
+	"clean-architecture/model/entity"
+	"testing"
+)
+
+func TestOptionMatrix(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []*entity.ProductOption
+		wantSize int
+	}{
+		{
+			name:     "no options is one empty combination",
+			options:  nil,
+			wantSize: 1,
+		},
+		{
+			name: "one option",
+			options: []*entity.ProductOption{
+				{Name: "flavor", Values: []string{"mango", "grape", "mint"}},
+			},
+			wantSize: 3,
+		},
+		{
+			name: "three options",
+			options: []*entity.ProductOption{
+				{Name: "flavor", Values: []string{"mango", "grape", "mint"}},
+				{Name: "nicotine", Values: []string{"3mg", "6mg"}},
+				{Name: "size", Values: []string{"30ml", "60ml", "100ml", "120ml"}},
+			},
+			wantSize: 24,
+		},
+		{
+			name: "an option without values leaves nothing",
+			options: []*entity.ProductOption{
+				{Name: "flavor", Values: []string{"mango", "grape"}},
+				{Name: "nicotine", Values: nil},
+			},
+			wantSize: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combinations := optionMatrix(tt.options)
+			if len(combinations) != tt.wantSize {
+				t.Fatalf("len(optionMatrix()) = %d, want %d", len(combinations), tt.wantSize)
+			}
+
+			seen := make(map[string]bool, len(combinations))
+			for _, combination := range combinations {
+				if len(combination) != len(tt.options) {
+					t.Errorf("combination %v has %d options, want %d", combination, len(combination), len(tt.options))
+				}
+				key := entity.OptionKey(combination)
+				if seen[key] {
+					t.Errorf("combination %v appears twice", combination)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+func TestGenerateSKU(t *testing.T) {
+	options := []*entity.ProductOption{
+		{Name: "flavor", Values: []string{"Mango Ice", "grape"}},
+		{Name: "nicotine", Values: []string{"3 mg", "6mg"}},
+	}
+
+	tests := []struct {
+		name        string
+		prefix      string
+		combination map[string]string
+		want        string
+	}{
+		{
+			name:        "values are upper cased and joined in option order",
+			prefix:      "liq",
+			combination: map[string]string{"nicotine": "6mg", "flavor": "grape"},
+			want:        "LIQ-GRAPE-6MG",
+		},
+		{
+			name:        "spaces and punctuation are dropped",
+			prefix:      "liq-01",
+			combination: map[string]string{"flavor": "Mango Ice", "nicotine": "3 mg"},
+			want:        "LIQ01-MANGOICE-3MG",
+		},
+		{
+			name:        "non ascii letters are dropped",
+			prefix:      "liq",
+			combination: map[string]string{"flavor": "café", "nicotine": "3mg"},
+			want:        "LIQ-CAF-3MG",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generateSKU(tt.prefix, options, tt.combination); got != tt.want {
+				t.Errorf("generateSKU() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSKUParts(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		options []*entity.ProductOption
+		wantErr bool
+	}{
+		{
+			name:   "distinct values",
+			prefix: "liq",
+			options: []*entity.ProductOption{
+				{Name: "nicotine", Values: []string{"3mg", "6mg"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "prefix without ascii letters or digits",
+			prefix:  "--",
+			wantErr: true,
+		},
+		{
+			name:   "values giving the same SKU part",
+			prefix: "liq",
+			options: []*entity.ProductOption{
+				{Name: "nicotine", Values: []string{"3 mg", "3mg"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "value without ascii letters or digits",
+			prefix: "liq",
+			options: []*entity.ProductOption{
+				{Name: "flavor", Values: []string{"mango", "★"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSKUParts(tt.prefix, tt.options); (err != nil) != tt.wantErr {
+				t.Errorf("checkSKUParts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}